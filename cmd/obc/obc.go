@@ -0,0 +1,175 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jparr721/obsidian/internal/astprinter"
+	"github.com/jparr721/obsidian/internal/runtime"
+)
+
+const usage = `usage: obc <command> [arguments]
+
+commands:
+  run [-backend vm|tree] [-I dir]... <file.ob|file.obc>
+                    tokenize, parse and run a file, object files run on the vm.
+                    imports are looked up next to the importing file, then in
+                    each -I dir, then in OBSIDIAN_PATH
+  build [-o file.obc] <file.ob>
+                    compile a file to a bytecode object file
+  disasm <file.ob|file.obc>
+                    print the bytecode of a file with its source lines
+  check <file.ob>   tokenize and parse a file without running it
+  repl              start an interactive session
+  ast [-format sexpr|json|dot] <file.ob>
+                    print the parsed syntax tree
+  tokens <file.ob>  print the token stream
+`
+
+// searchPaths collects every -I flag in the order given
+type searchPaths []string
+
+func (s *searchPaths) String() string {
+	return strings.Join(*s, string(os.PathListSeparator))
+}
+
+func (s *searchPaths) Set(dir string) error {
+	*s = append(*s, dir)
+	return nil
+}
+
+const (
+	exitOk    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		return exitUsage
+	}
+
+	command, args := args[0], args[1:]
+
+	// obc [-backend vm|tree] <file.ob> is shorthand for obc run
+	if strings.HasSuffix(command, ".ob") || strings.HasSuffix(command, ".obsidian") || strings.HasSuffix(command, ".obc") || strings.HasPrefix(command, "-backend") || strings.HasPrefix(command, "--backend") {
+		command, args = "run", append([]string{command}, args...)
+	}
+
+	rt := runtime.NewObcRT()
+	format := astprinter.FormatSexpr
+
+	if command == "run" {
+		flags := flag.NewFlagSet("run", flag.ContinueOnError)
+		backendName := flags.String("backend", "tree", "execution backend: vm or tree")
+
+		var includes searchPaths
+		flags.Var(&includes, "I", "directory to search for imported modules, may be repeated")
+
+		if err := flags.Parse(args); err != nil {
+			return exitUsage
+		}
+
+		// Earlier flags take priority, so add them last
+		for n := len(includes) - 1; n >= 0; n-- {
+			rt.AddSearchPath(includes[n])
+		}
+
+		backend, err := runtime.ParseBackend(*backendName)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+
+		rt.SetBackend(backend)
+		args = flags.Args()
+	}
+
+	output := ""
+
+	if command == "build" {
+		flags := flag.NewFlagSet("build", flag.ContinueOnError)
+		flags.StringVar(&output, "o", "", "object file to write, defaults to the source name with .obc")
+
+		if err := flags.Parse(args); err != nil {
+			return exitUsage
+		}
+
+		args = flags.Args()
+	}
+
+	if command == "ast" {
+		flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+		formatName := flags.String("format", "sexpr", "output format: sexpr, json or dot")
+
+		if err := flags.Parse(args); err != nil {
+			return exitUsage
+		}
+
+		var err error
+		if format, err = astprinter.ParseFormat(*formatName); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitUsage
+		}
+
+		args = flags.Args()
+	}
+
+	switch command {
+	case "repl":
+		rt.Repl(os.Stdin, os.Stdout)
+		return exitOk
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+		return exitOk
+	case "run", "check", "ast", "tokens", "build", "disasm":
+		if len(args) != 1 {
+			fmt.Fprintf(os.Stderr, "obc %s: expected exactly one file\n\n%s", command, usage)
+			return exitUsage
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "obc: unknown command '%s'\n\n%s", command, usage)
+		return exitUsage
+	}
+
+	file := args[0]
+
+	switch command {
+	case "run":
+		rt.Run(file)
+	case "check":
+		rt.Check(file)
+	case "build":
+		if output == "" {
+			output = runtime.ObjectPath(file)
+		}
+
+		rt.Build(file, output)
+	case "disasm":
+		rt.Disassemble(file, os.Stdout)
+	case "ast":
+		statements := rt.Statements(file)
+		if !rt.DidError() {
+			fmt.Print(astprinter.NewAstPrinterWithFormat(format).Print(statements))
+		}
+	case "tokens":
+		for _, token := range rt.Tokens(file) {
+			fmt.Printf("%4d %-14s %-12q %v\n", token.Line, token.Variant, token.Lexeme, token.Literal)
+		}
+	}
+
+	if rt.DidError() {
+		rt.ReportErrors(os.Stderr)
+		return exitError
+	}
+
+	rt.ReportWarnings(os.Stderr)
+	return exitOk
+}
@@ -0,0 +1,388 @@
+package astprinter
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/jparr721/obsidian/internal/expression"
+	"github.com/jparr721/obsidian/internal/statement"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+// Format selects how the printer renders a tree
+type Format int
+
+const (
+	// FormatSexpr renders one S-expression per top level statement
+	FormatSexpr Format = iota
+
+	// FormatJSON renders an indented JSON array of nodes with their source lines
+	FormatJSON
+
+	// FormatDot renders a Graphviz digraph
+	FormatDot
+)
+
+// ParseFormat maps a format name from the command line to a Format
+func ParseFormat(name string) (Format, error) {
+	switch name {
+	case "sexpr":
+		return FormatSexpr, nil
+	case "json":
+		return FormatJSON, nil
+	case "dot":
+		return FormatDot, nil
+	}
+
+	return FormatSexpr, fmt.Errorf("unknown ast format '%s', expected sexpr, json or dot", name)
+}
+
+// Node is a format neutral view of a single AST node
+type Node struct {
+	Kind     string   `json:"kind"`
+	Name     string   `json:"name,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Params   []string `json:"params,omitempty"`
+	Children []*Node  `json:"children,omitempty"`
+
+	// head is the S-expression head, atoms leave it empty
+	head string
+}
+
+// AstPrinter is a printer module for visually representing the AST
+type AstPrinter struct {
+	format Format
+}
+
+// NewAstPrinter returns a new pointer to the ast printer module using S-expressions
+func NewAstPrinter() *AstPrinter {
+	return &AstPrinter{FormatSexpr}
+}
+
+// NewAstPrinterWithFormat returns a printer for the given output format
+func NewAstPrinterWithFormat(format Format) *AstPrinter {
+	return &AstPrinter{format}
+}
+
+// Print renders a list of top level statements in the printer's format
+func (a *AstPrinter) Print(statements []statement.Statement) string {
+	nodes := a.Nodes(statements)
+
+	switch a.format {
+	case FormatJSON:
+		return renderJSON(nodes)
+	case FormatDot:
+		return renderDot(nodes)
+	default:
+		return renderSexpr(nodes)
+	}
+}
+
+// PrintExpression renders a single expression as an S-expression
+func (a *AstPrinter) PrintExpression(e expression.Expression) string {
+	return sexpr(a.expression(e))
+}
+
+// Nodes converts statements into their format neutral tree
+func (a *AstPrinter) Nodes(statements []statement.Statement) []*Node {
+	return a.statements(statements)
+}
+
+func (a *AstPrinter) statement(s statement.Statement) *Node {
+	if s == nil {
+		return atom("nil", "nil", 0)
+	}
+
+	value, _ := s.Accept(a)
+	return value.(*Node)
+}
+
+func (a *AstPrinter) expression(e expression.Expression) *Node {
+	if e == nil {
+		return atom("nil", "nil", 0)
+	}
+
+	value, _ := e.Accept(a)
+	return value.(*Node)
+}
+
+func (a *AstPrinter) expressions(exprs []expression.Expression) []*Node {
+	nodes := make([]*Node, 0, len(exprs))
+	for _, e := range exprs {
+		nodes = append(nodes, a.expression(e))
+	}
+
+	return nodes
+}
+
+func (a *AstPrinter) statements(stmts []statement.Statement) []*Node {
+	nodes := make([]*Node, 0, len(stmts))
+	for _, s := range stmts {
+		nodes = append(nodes, a.statement(s))
+	}
+
+	return nodes
+}
+
+func atom(kind, name string, line int) *Node {
+	return &Node{Kind: kind, Name: name, Line: line}
+}
+
+func node(kind, head string, line int, children ...*Node) (interface{}, error) {
+	return &Node{Kind: kind, Line: line, Children: children, head: head}, nil
+}
+
+func operator(kind string, op tokens.Token, children ...*Node) (interface{}, error) {
+	return &Node{Kind: kind, Name: op.Lexeme, Line: op.Line, Children: children, head: op.Lexeme}, nil
+}
+
+func named(kind, head string, name tokens.Token, children ...*Node) (interface{}, error) {
+	return &Node{Kind: kind, Name: name.Lexeme, Line: name.Line, Children: children, head: head}, nil
+}
+
+func params(arguments []tokens.Token) []string {
+	names := make([]string, 0, len(arguments))
+	for _, arg := range arguments {
+		names = append(names, arg.Lexeme)
+	}
+
+	return names
+}
+
+func literal(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// VisitBinaryExpression prints a binary operation
+func (a *AstPrinter) VisitBinaryExpression(e *expression.BinaryExpression) (interface{}, error) {
+	return operator("binary", e.Operator, a.expression(e.Left), a.expression(e.Right))
+}
+
+// VisitGroupingExpression prints a parenthesized expression
+func (a *AstPrinter) VisitGroupingExpression(e *expression.GroupingExpression) (interface{}, error) {
+	return node("grouping", "group", 0, a.expression(e.Expression.(expression.Expression)))
+}
+
+// VisitLiteralExpression prints a literal value
+func (a *AstPrinter) VisitLiteralExpression(e *expression.LiteralExpression) (interface{}, error) {
+	return atom("literal", literal(e.Value), 0), nil
+}
+
+// VisitUnaryExpression prints a prefix operation
+func (a *AstPrinter) VisitUnaryExpression(e *expression.UnaryExpression) (interface{}, error) {
+	return operator("unary", e.Operator, a.expression(e.Right.(expression.Expression)))
+}
+
+// VisitVariableExpression prints a variable reference
+func (a *AstPrinter) VisitVariableExpression(e *expression.VariableExpression) (interface{}, error) {
+	return atom("variable", e.Name.Lexeme, e.Name.Line), nil
+}
+
+// VisitAssignExpression prints an assignment
+func (a *AstPrinter) VisitAssignExpression(e *expression.AssignExpression) (interface{}, error) {
+	return named("assign", "=", e.Name, a.expression(e.Value))
+}
+
+// VisitLogicalExpression prints a short circuiting operation
+func (a *AstPrinter) VisitLogicalExpression(e *expression.LogicalExpression) (interface{}, error) {
+	return operator("logical", e.Operator, a.expression(e.Left), a.expression(e.Right))
+}
+
+// VisitCallExpression prints a call and its arguments
+func (a *AstPrinter) VisitCallExpression(e *expression.CallExpression) (interface{}, error) {
+	return node("call", "call", e.Paren.Line, append([]*Node{a.expression(e.Callee)}, a.expressions(e.Arguments)...)...)
+}
+
+// VisitExpressionStatement prints an expression statement
+func (a *AstPrinter) VisitExpressionStatement(s *statement.ExpressionStatement) (interface{}, error) {
+	return node("expression", "expr", 0, a.expression(s.Expression))
+}
+
+// VisitPrintStatement prints a print statement
+func (a *AstPrinter) VisitPrintStatement(s *statement.PrintStatement) (interface{}, error) {
+	return node("print", "print", 0, a.expression(s.Expression))
+}
+
+// VisitVariableStatement prints a variable declaration
+func (a *AstPrinter) VisitVariableStatement(s *statement.VariableStatement) (interface{}, error) {
+	if s.Initializer == nil {
+		return named("var", "var", s.Name)
+	}
+
+	return named("var", "var", s.Name, a.expression(s.Initializer))
+}
+
+// VisitBlockStatement prints a block and its statements
+func (a *AstPrinter) VisitBlockStatement(s *statement.BlockStatement) (interface{}, error) {
+	return node("block", "block", 0, a.statements(s.Statements)...)
+}
+
+// VisitIfStatement prints a conditional
+func (a *AstPrinter) VisitIfStatement(s *statement.IfStatement) (interface{}, error) {
+	if s.ElseBranch == nil {
+		return node("if", "if", 0, a.expression(s.Condition), a.statement(s.ThenBranch))
+	}
+
+	return node("if", "if", 0, a.expression(s.Condition), a.statement(s.ThenBranch), a.statement(s.ElseBranch))
+}
+
+// VisitWhileStatement prints a loop, named after its label when it has one. A for loop's
+// increment follows the body.
+func (a *AstPrinter) VisitWhileStatement(s *statement.WhileStatement) (interface{}, error) {
+	children := []*Node{a.expression(s.Condition), a.statement(s.Body)}
+	if s.Increment != nil {
+		children = append(children, a.expression(s.Increment))
+	}
+
+	if s.Label.Lexeme != "" {
+		return named("while", "while", s.Label, children...)
+	}
+
+	return node("while", "while", 0, children...)
+}
+
+// VisitBreakStatement prints a break and the label it leaves
+func (a *AstPrinter) VisitBreakStatement(s *statement.BreakStatement) (interface{}, error) {
+	return loopControl("break", s.Instance, s.Label)
+}
+
+// VisitContinueStatement prints a continue and the label it goes on with
+func (a *AstPrinter) VisitContinueStatement(s *statement.ContinueStatement) (interface{}, error) {
+	return loopControl("continue", s.Instance, s.Label)
+}
+
+func loopControl(kind string, instance, label tokens.Token) (interface{}, error) {
+	if label.Lexeme == "" {
+		return node(kind, kind, instance.Line)
+	}
+
+	return named(kind, kind, label)
+}
+
+// VisitFunctionStatement prints a function declaration
+func (a *AstPrinter) VisitFunctionStatement(s *statement.FunctionStatement) (interface{}, error) {
+	n, _ := named("function", "fun", s.Name, a.statements(s.Body)...)
+	n.(*Node).Params = params(s.Arguments)
+	return n, nil
+}
+
+// VisitReturnStatement prints a return
+func (a *AstPrinter) VisitReturnStatement(s *statement.ReturnStatement) (interface{}, error) {
+	if s.Value == nil {
+		return node("return", "return", s.Keyword.Line)
+	}
+
+	return node("return", "return", s.Keyword.Line, a.expression(s.Value))
+}
+
+// VisitGetExpression prints a property access
+func (a *AstPrinter) VisitGetExpression(e *expression.GetExpression) (interface{}, error) {
+	return named("get", "get", e.Name, a.expression(e.Object))
+}
+
+// VisitSetExpression prints a property assignment
+func (a *AstPrinter) VisitSetExpression(e *expression.SetExpression) (interface{}, error) {
+	return named("set", "set", e.Name, a.expression(e.Object), a.expression(e.Value))
+}
+
+// VisitThisExpression prints a reference to the current instance
+func (a *AstPrinter) VisitThisExpression(e *expression.ThisExpression) (interface{}, error) {
+	return atom("this", "this", e.Keyword.Line), nil
+}
+
+// VisitClassStatement prints a class declaration and its methods
+func (a *AstPrinter) VisitClassStatement(s *statement.ClassStatement) (interface{}, error) {
+	methods := make([]*Node, 0, len(s.Methods))
+	for _, method := range s.Methods {
+		methods = append(methods, a.statement(method))
+	}
+
+	class, _ := named("class", "class", s.Name, methods...)
+
+	// Hang the superclass off as the first child so every format shows it
+	if s.Superclass != nil {
+		superclass, _ := named("superclass", "<", s.Superclass.Name)
+		class.(*Node).Children = append([]*Node{superclass.(*Node)}, methods...)
+	}
+
+	return class, nil
+}
+
+// VisitImportStatement prints a module import and the name it is bound to
+func (a *AstPrinter) VisitImportStatement(s *statement.ImportStatement) (interface{}, error) {
+	return named("import", "import", s.Name, atom("literal", literal(s.Path.Literal), s.Path.Line))
+}
+
+// VisitThrowStatement prints a throw
+func (a *AstPrinter) VisitThrowStatement(s *statement.ThrowStatement) (interface{}, error) {
+	return node("throw", "throw", s.Keyword.Line, a.expression(s.Value))
+}
+
+// VisitTryStatement prints a try with its body first and then whichever clauses it has
+func (a *AstPrinter) VisitTryStatement(s *statement.TryStatement) (interface{}, error) {
+	body, _ := node("block", "block", s.Keyword.Line, a.statements(s.Body)...)
+	clauses := []*Node{body.(*Node)}
+
+	if s.Catch != nil {
+		catch, _ := named("catch", "catch", s.CatchName, a.statements(s.Catch)...)
+		clauses = append(clauses, catch.(*Node))
+	}
+
+	if s.Finally != nil {
+		finally, _ := node("finally", "finally", 0, a.statements(s.Finally)...)
+		clauses = append(clauses, finally.(*Node))
+	}
+
+	return node("try", "try", s.Keyword.Line, clauses...)
+}
+
+// VisitSuperExpression prints a superclass method lookup
+func (a *AstPrinter) VisitSuperExpression(e *expression.SuperExpression) (interface{}, error) {
+	return named("super", "super", e.Method)
+}
+
+// VisitFunctionExpression prints an anonymous function
+func (a *AstPrinter) VisitFunctionExpression(e *expression.FunctionExpression) (interface{}, error) {
+	n, _ := node("lambda", "fun", e.Keyword.Line, a.statements(e.Body.([]statement.Statement))...)
+	n.(*Node).Params = params(e.Arguments)
+	return n, nil
+}
+
+// VisitInterpolationExpression prints an interpolated string as its parts
+func (a *AstPrinter) VisitInterpolationExpression(e *expression.InterpolationExpression) (interface{}, error) {
+	return node("interpolation", "interpolate", e.Start.Line, a.expressions(e.Parts)...)
+}
+
+// VisitListExpression prints a list literal
+func (a *AstPrinter) VisitListExpression(e *expression.ListExpression) (interface{}, error) {
+	return node("list", "list", e.Bracket.Line, a.expressions(e.Elements)...)
+}
+
+// VisitIndexExpression prints a subscript read
+func (a *AstPrinter) VisitIndexExpression(e *expression.IndexExpression) (interface{}, error) {
+	return node("index", "index", e.Bracket.Line, a.expression(e.Object), a.expression(e.Index))
+}
+
+// VisitIndexSetExpression prints a subscript write
+func (a *AstPrinter) VisitIndexSetExpression(e *expression.IndexSetExpression) (interface{}, error) {
+	return node("indexSet", "index=", e.Bracket.Line, a.expression(e.Object), a.expression(e.Index), a.expression(e.Value))
+}
+
+// VisitMapExpression prints a map literal as alternating keys and values
+func (a *AstPrinter) VisitMapExpression(e *expression.MapExpression) (interface{}, error) {
+	entries := make([]*Node, 0, len(e.Keys)*2)
+	for n := range e.Keys {
+		entries = append(entries, a.expression(e.Keys[n]), a.expression(e.Values[n]))
+	}
+
+	return node("map", "map", e.Brace.Line, entries...)
+}
@@ -0,0 +1,68 @@
+package astprinter
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jparr721/obsidian/internal/parser"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var goldenFormats = map[string]Format{
+	".sexpr": FormatSexpr,
+	".json":  FormatJSON,
+	".dot":   FormatDot,
+}
+
+func TestGolden(t *testing.T) {
+	sources, err := filepath.Glob("testdata/*.ob")
+
+	if err != nil || len(sources) == 0 {
+		t.Fatalf("no golden sources found: %v", err)
+	}
+
+	for _, source := range sources {
+		src, err := ioutil.ReadFile(source)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		toks, tokErr := tokens.NewTokenizer(string(src)).ScanTokens()
+		if tokErr != nil {
+			t.Fatalf("%s: %v", source, tokErr)
+		}
+
+		statements, parseErrs := parser.NewParser(toks).Parse()
+		if len(parseErrs) > 0 {
+			t.Fatalf("%s: %v", source, parseErrs)
+		}
+
+		for extension, format := range goldenFormats {
+			golden := strings.TrimSuffix(source, ".ob") + extension
+			output := NewAstPrinterWithFormat(format).Print(statements)
+
+			if *update {
+				if err := ioutil.WriteFile(golden, []byte(output), 0644); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+
+			expected, err := ioutil.ReadFile(golden)
+
+			if err != nil {
+				t.Fatalf("%s: %v (run with -update to create it)", golden, err)
+			}
+
+			if output != string(expected) {
+				t.Errorf("%s: output did not match golden file\n got:\n%s\nwant:\n%s", golden, output, expected)
+			}
+		}
+	}
+}
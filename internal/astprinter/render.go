@@ -0,0 +1,109 @@
+package astprinter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func renderSexpr(nodes []*Node) string {
+	builder := strings.Builder{}
+
+	for _, n := range nodes {
+		builder.WriteString(sexpr(n))
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// sexpr renders a node as (head name (params) children...), atoms render as their name
+func sexpr(n *Node) string {
+	if n.head == "" {
+		return n.Name
+	}
+
+	parts := []string{n.head}
+
+	// Operators already use their lexeme as the head
+	if n.Name != "" && n.Name != n.head {
+		parts = append(parts, n.Name)
+	}
+
+	if n.Params != nil {
+		parts = append(parts, "("+strings.Join(n.Params, " ")+")")
+	}
+
+	for _, child := range n.Children {
+		parts = append(parts, sexpr(child))
+	}
+
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+func renderJSON(nodes []*Node) string {
+	out, err := json.MarshalIndent(nodes, "", "  ")
+
+	// Nodes only hold strings, ints and slices so this cannot fail
+	if err != nil {
+		panic(err)
+	}
+
+	return string(out) + "\n"
+}
+
+// label is the text shown inside a node of the DOT graph
+func label(n *Node) string {
+	if n.head == "" {
+		return n.Name
+	}
+
+	text := n.head
+
+	if n.Name != "" && n.Name != n.head {
+		text += " " + n.Name
+	}
+
+	if n.Params != nil {
+		text += " (" + strings.Join(n.Params, " ") + ")"
+	}
+
+	return text
+}
+
+func renderDot(nodes []*Node) string {
+	builder := strings.Builder{}
+	next := 0
+
+	var walk func(n *Node) int
+	walk = func(n *Node) int {
+		id := next
+		next++
+
+		text := strconv.Quote(label(n))
+		if n.Line > 0 {
+			// Splice the line onto a second row of the quoted label
+			text = fmt.Sprintf("%s\\nline %d\"", text[:len(text)-1], n.Line)
+		}
+		fmt.Fprintf(&builder, "  n%d [label=%s];\n", id, text)
+
+		for _, child := range n.Children {
+			fmt.Fprintf(&builder, "  n%d -> n%d;\n", id, walk(child))
+		}
+
+		return id
+	}
+
+	builder.WriteString("digraph ast {\n")
+	builder.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	builder.WriteString("  root [label=\"program\"];\n")
+
+	for _, n := range nodes {
+		fmt.Fprintf(&builder, "  root -> n%d;\n", walk(n))
+	}
+
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
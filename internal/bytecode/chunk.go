@@ -0,0 +1,62 @@
+package bytecode
+
+// Chunk is a compiled sequence of instructions with the constants they refer to
+type Chunk struct {
+	Code      []byte
+	Constants []interface{}
+
+	// Lines holds the source line of every byte in Code, for runtime errors
+	Lines []int
+
+	// Spans holds where on its line the token behind every byte in Code sits
+	Spans []Span
+}
+
+// Span is the column and length of a token, both counted in runes
+type Span struct {
+	Column int
+	Length int
+}
+
+func NewChunk() *Chunk {
+	return &Chunk{make([]byte, 0), make([]interface{}, 0), make([]int, 0), make([]Span, 0)}
+}
+
+// Write appends a byte of code from the given source line
+func (c *Chunk) Write(b byte, line int) {
+	c.WriteSpan(b, line, Span{})
+}
+
+// WriteSpan appends a byte of code from the token at span on the given source line
+func (c *Chunk) WriteSpan(b byte, line int, span Span) {
+	c.Code = append(c.Code, b)
+	c.Lines = append(c.Lines, line)
+	c.Spans = append(c.Spans, span)
+}
+
+// AddConstant stores value in the constant pool and returns its index. The compiler shares
+// slots between equal values before it gets here.
+func (c *Chunk) AddConstant(value interface{}) int {
+	c.Constants = append(c.Constants, value)
+	return len(c.Constants) - 1
+}
+
+// Function is a compiled function body, the virtual machine wraps it in a closure to call it
+type Function struct {
+	Name         string
+	Arity        int
+	UpvalueCount int
+	Chunk        *Chunk
+}
+
+func NewFunction(name string) *Function {
+	return &Function{name, 0, 0, NewChunk()}
+}
+
+func (f *Function) String() string {
+	if f.Name == "" {
+		return "<script>"
+	}
+
+	return "<fn " + f.Name + ">"
+}
@@ -0,0 +1,94 @@
+package bytecode
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Disassemble writes a readable listing of fn and every function nested in its constants
+func Disassemble(w io.Writer, fn *Function) {
+	fmt.Fprintf(w, "== %s ==\n", fn)
+
+	code := fn.Chunk.Code
+	for offset := 0; offset < len(code); {
+		offset = disassembleInstruction(w, fn.Chunk, offset)
+	}
+
+	for _, constant := range fn.Chunk.Constants {
+		if nested, ok := constant.(*Function); ok {
+			fmt.Fprintln(w)
+			Disassemble(w, nested)
+		}
+	}
+}
+
+// disassembleInstruction writes the instruction at offset and returns where the next begins
+func disassembleInstruction(w io.Writer, chunk *Chunk, offset int) int {
+	fmt.Fprintf(w, "%04d ", offset)
+
+	// Only mark a line when it changes, like a listing in a debugger
+	if offset > 0 && chunk.Lines[offset] == chunk.Lines[offset-1] {
+		fmt.Fprint(w, "   | ")
+	} else {
+		fmt.Fprintf(w, "%4d ", chunk.Lines[offset])
+	}
+
+	op := OpCode(chunk.Code[offset])
+	short := func(at int) int {
+		return int(chunk.Code[at])<<8 | int(chunk.Code[at+1])
+	}
+
+	switch op {
+	case OpConstant, OpGetGlobal, OpDefineGlobal, OpSetGlobal, OpGetProperty, OpSetProperty,
+		OpGetSuper, OpClass, OpMethod, OpImport:
+		index := short(offset + 1)
+		fmt.Fprintf(w, "%-18s %4d %s\n", op, index, constantString(chunk.Constants[index]))
+		return offset + 3
+	case OpGetLocal, OpSetLocal, OpGetUpvalue, OpSetUpvalue, OpCall:
+		fmt.Fprintf(w, "%-18s %4d\n", op, chunk.Code[offset+1])
+		return offset + 2
+	case OpList, OpMap:
+		fmt.Fprintf(w, "%-18s %4d\n", op, short(offset+1))
+		return offset + 3
+	case OpJump, OpJumpIfFalse, OpTry:
+		fmt.Fprintf(w, "%-18s %4d -> %d\n", op, offset, offset+3+short(offset+1))
+		return offset + 3
+	case OpLoop:
+		fmt.Fprintf(w, "%-18s %4d -> %d\n", op, offset, offset+3-short(offset+1))
+		return offset + 3
+	case OpClosure:
+		index := short(offset + 1)
+		fn := chunk.Constants[index].(*Function)
+		fmt.Fprintf(w, "%-18s %4d %s\n", op, index, fn)
+
+		offset += 3
+		for n := 0; n < fn.UpvalueCount; n++ {
+			kind := "upvalue"
+			if chunk.Code[offset] == 1 {
+				kind = "local"
+			}
+
+			fmt.Fprintf(w, "%04d    |                     %s %d\n", offset, kind, chunk.Code[offset+1])
+			offset += 2
+		}
+
+		return offset
+	}
+
+	fmt.Fprintln(w, op)
+	return offset + 1
+}
+
+func constantString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	}
+
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,16 @@
+package bytecode
+
+import "fmt"
+
+// ObjectError represents an object file that is corrupt or was written by another version
+type ObjectError struct {
+	message string
+}
+
+func newObjectError(format string, args ...interface{}) *ObjectError {
+	return &ObjectError{fmt.Sprintf(format, args...)}
+}
+
+func (o *ObjectError) Error() string {
+	return "ObjectError: " + o.message
+}
@@ -0,0 +1,331 @@
+package bytecode
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// object.go reads and writes compiled scripts as .obc object files. A file is the magic
+// header, a version, then the script function. Functions are written as their name, arity,
+// upvalue count, code, run length encoded line and span tables and constant pool, with nested
+// functions stored inline in the pool.
+
+// Magic opens every object file
+const Magic = "\x7fOBC"
+
+// Version is bumped whenever the instruction set or the file layout changes
+const Version = 6
+
+const (
+	tagNumber byte = iota
+	tagString
+	tagFunction
+	tagInteger
+)
+
+// Encode writes script to w as an object file
+func Encode(w io.Writer, script *Function) error {
+	e := &encoder{w: bufio.NewWriter(w)}
+
+	e.bytes([]byte(Magic))
+	e.uint(Version)
+	e.function(script)
+
+	if e.err != nil {
+		return e.err
+	}
+
+	return e.w.Flush()
+}
+
+// Decode reads an object file written by Encode
+func Decode(r io.Reader) (*Function, error) {
+	d := &decoder{r: bufio.NewReader(r)}
+
+	magic := make([]byte, len(Magic))
+	if _, err := io.ReadFull(d.r, magic); err != nil || string(magic) != Magic {
+		return nil, newObjectError("not an obsidian object file")
+	}
+
+	if version := d.uint(); d.err == nil && version != Version {
+		return nil, newObjectError("object file version %d is not supported, expected version %d", version, Version)
+	}
+
+	script := d.function()
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	if err := verify(script); err != nil {
+		return nil, err
+	}
+
+	return script, nil
+}
+
+// encoder writes primitives, the first write error sticks and the rest are skipped
+type encoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *encoder) bytes(b []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *encoder) uint(value uint64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	e.bytes(buf[:binary.PutUvarint(buf, value)])
+}
+
+func (e *encoder) int(value int64) {
+	buf := make([]byte, binary.MaxVarintLen64)
+	e.bytes(buf[:binary.PutVarint(buf, value)])
+}
+
+func (e *encoder) string(s string) {
+	e.uint(uint64(len(s)))
+	e.bytes([]byte(s))
+}
+
+func (e *encoder) function(fn *Function) {
+	e.string(fn.Name)
+	e.uint(uint64(fn.Arity))
+	e.uint(uint64(fn.UpvalueCount))
+
+	e.uint(uint64(len(fn.Chunk.Code)))
+	e.bytes(fn.Chunk.Code)
+	e.lines(fn.Chunk.Lines)
+	e.spans(fn.Chunk.Spans)
+
+	e.uint(uint64(len(fn.Chunk.Constants)))
+	for _, constant := range fn.Chunk.Constants {
+		e.constant(constant)
+	}
+}
+
+// lines writes the line table as runs of (line, count) pairs
+func (e *encoder) lines(lines []int) {
+	runs := make([][2]int, 0)
+	for _, line := range lines {
+		if len(runs) > 0 && runs[len(runs)-1][0] == line {
+			runs[len(runs)-1][1]++
+			continue
+		}
+
+		runs = append(runs, [2]int{line, 1})
+	}
+
+	e.uint(uint64(len(runs)))
+	for _, run := range runs {
+		e.uint(uint64(run[0]))
+		e.uint(uint64(run[1]))
+	}
+}
+
+// spans writes the span table as runs of (column, length, count) triples
+func (e *encoder) spans(spans []Span) {
+	type run struct {
+		span  Span
+		count int
+	}
+
+	runs := make([]run, 0)
+	for _, span := range spans {
+		if len(runs) > 0 && runs[len(runs)-1].span == span {
+			runs[len(runs)-1].count++
+			continue
+		}
+
+		runs = append(runs, run{span, 1})
+	}
+
+	e.uint(uint64(len(runs)))
+	for _, r := range runs {
+		e.uint(uint64(r.span.Column))
+		e.uint(uint64(r.span.Length))
+		e.uint(uint64(r.count))
+	}
+}
+
+func (e *encoder) constant(value interface{}) {
+	switch v := value.(type) {
+	case float64:
+		buf := make([]byte, 8)
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(v))
+		e.bytes([]byte{tagNumber})
+		e.bytes(buf)
+	case int64:
+		e.bytes([]byte{tagInteger})
+		e.int(v)
+	case string:
+		e.bytes([]byte{tagString})
+		e.string(v)
+	case *Function:
+		e.bytes([]byte{tagFunction})
+		e.function(v)
+	default:
+		if e.err == nil {
+			e.err = newObjectError("cannot encode constant %v", value)
+		}
+	}
+}
+
+// decoder reads primitives, the first error sticks and later reads return zero values
+type decoder struct {
+	r   *bufio.Reader
+	err error
+}
+
+func (d *decoder) fail(err error) {
+	if d.err != nil {
+		return
+	}
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = newObjectError("unexpected end of object file")
+	}
+
+	d.err = err
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil {
+		return 0
+	}
+
+	b, err := d.r.ReadByte()
+	d.fail(err)
+	return b
+}
+
+func (d *decoder) bytes(n uint64) []byte {
+	if d.err != nil {
+		return nil
+	}
+
+	b := make([]byte, n)
+	_, err := io.ReadFull(d.r, b)
+	d.fail(err)
+	return b
+}
+
+func (d *decoder) uint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	value, err := binary.ReadUvarint(d.r)
+	d.fail(err)
+	return value
+}
+
+func (d *decoder) int() int64 {
+	if d.err != nil {
+		return 0
+	}
+
+	value, err := binary.ReadVarint(d.r)
+	d.fail(err)
+	return value
+}
+
+// length reads a count and rejects counts larger than the rest of a sane file
+func (d *decoder) length() uint64 {
+	n := d.uint()
+
+	if n > math.MaxInt32 {
+		d.fail(newObjectError("length %d is out of range", n))
+		return 0
+	}
+
+	return n
+}
+
+func (d *decoder) string() string {
+	return string(d.bytes(d.length()))
+}
+
+func (d *decoder) function() *Function {
+	fn := NewFunction(d.string())
+	fn.Arity = int(d.uint())
+	fn.UpvalueCount = int(d.uint())
+
+	fn.Chunk.Code = d.bytes(d.length())
+	fn.Chunk.Lines = d.lines()
+
+	if d.err == nil && len(fn.Chunk.Lines) != len(fn.Chunk.Code) {
+		d.fail(newObjectError("line table of %s does not match its code", fn))
+	}
+
+	fn.Chunk.Spans = d.spans()
+
+	if d.err == nil && len(fn.Chunk.Spans) != len(fn.Chunk.Code) {
+		d.fail(newObjectError("span table of %s does not match its code", fn))
+	}
+
+	count := d.length()
+	for n := uint64(0); n < count && d.err == nil; n++ {
+		fn.Chunk.Constants = append(fn.Chunk.Constants, d.constant())
+	}
+
+	return fn
+}
+
+func (d *decoder) lines() []int {
+	lines := make([]int, 0)
+
+	runs := d.length()
+	for n := uint64(0); n < runs && d.err == nil; n++ {
+		line, count := int(d.uint()), d.length()
+
+		for ; count > 0 && d.err == nil; count-- {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+func (d *decoder) spans() []Span {
+	spans := make([]Span, 0)
+
+	runs := d.length()
+	for n := uint64(0); n < runs && d.err == nil; n++ {
+		span := Span{int(d.length()), int(d.length())}
+
+		for count := d.length(); count > 0 && d.err == nil; count-- {
+			spans = append(spans, span)
+		}
+	}
+
+	return spans
+}
+
+func (d *decoder) constant() interface{} {
+	switch tag := d.byte(); tag {
+	case tagNumber:
+		buf := d.bytes(8)
+
+		if d.err != nil {
+			return nil
+		}
+
+		return math.Float64frombits(binary.LittleEndian.Uint64(buf))
+	case tagInteger:
+		return d.int()
+	case tagString:
+		return d.string()
+	case tagFunction:
+		return d.function()
+	default:
+		d.fail(newObjectError("unknown constant tag %d", tag))
+	}
+
+	return nil
+}
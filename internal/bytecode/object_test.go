@@ -0,0 +1,151 @@
+package bytecode
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sample builds a script with every kind of constant, including a nested closure
+func sample() *Function {
+	inner := NewFunction("inner")
+	inner.Arity = 1
+	inner.UpvalueCount = 1
+	inner.Chunk.Write(byte(OpGetUpvalue), 2)
+	inner.Chunk.Write(0, 2)
+	inner.Chunk.WriteSpan(byte(OpReturn), 3, Span{Column: 5, Length: 6})
+
+	script := NewFunction("")
+	script.Chunk.Write(byte(OpConstant), 1)
+	script.Chunk.Write(0, 1)
+	script.Chunk.Write(byte(script.Chunk.AddConstant(1.5)), 1)
+	script.Chunk.Write(byte(OpClosure), 2)
+	script.Chunk.Write(0, 2)
+	script.Chunk.Write(byte(script.Chunk.AddConstant(inner)), 2)
+	script.Chunk.Write(1, 2)
+	script.Chunk.Write(1, 2)
+	script.Chunk.Write(byte(OpDefineGlobal), 2)
+	script.Chunk.Write(0, 2)
+	script.Chunk.Write(byte(script.Chunk.AddConstant("name")), 2)
+	script.Chunk.Write(byte(OpConstant), 3)
+	script.Chunk.Write(0, 3)
+	script.Chunk.Write(byte(script.Chunk.AddConstant(int64(-42))), 3)
+	script.Chunk.Write(byte(OpPop), 3)
+	script.Chunk.Write(byte(OpNil), 4)
+	script.Chunk.Write(byte(OpReturn), 4)
+
+	return script
+}
+
+func TestRoundTrip(t *testing.T) {
+	script := sample()
+	buf := &bytes.Buffer{}
+
+	if err := Encode(buf, script); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := Decode(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(script, decoded) {
+		t.Errorf("expected %#v but got %#v", script, decoded)
+	}
+}
+
+// encoded builds a script from raw code and encodes it, skipping the compiler's guarantees
+func encoded(t *testing.T, code ...byte) []byte {
+	script := NewFunction("")
+	script.Chunk.AddConstant(1.5)
+
+	for _, b := range code {
+		script.Chunk.Write(b, 1)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := Encode(buf, script); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+type decodeTest struct {
+	Name     string
+	Data     []byte
+	Expected string
+}
+
+func TestDecodeErrors(t *testing.T) {
+	valid := &bytes.Buffer{}
+	if err := Encode(valid, sample()); err != nil {
+		t.Fatal(err)
+	}
+
+	data := valid.Bytes()
+	newerVersion := append([]byte(Magic), Version+1)
+
+	// The script's code follows the header, an empty name, its arity, upvalue count and code
+	// length. Point the low byte of its first constant load past the end of the pool.
+	badConstant := append([]byte{}, data...)
+	badConstant[len(Magic)+7] = 9
+
+	tests := []decodeTest{
+		{"empty file", []byte{}, "not an obsidian object file"},
+		{"wrong magic", []byte("#!obsidian"), "not an obsidian object file"},
+		{"newer version", newerVersion, "is not supported"},
+		{"truncated", data[:len(data)-3], "unexpected end of object file"},
+		{"constant out of range", badConstant, "constant 9 out of range"},
+		{"local out of range", encoded(t, byte(OpGetLocal), 0xff, byte(OpReturn)), "local 255 out of range"},
+		{"local above the stack", encoded(t, byte(OpNil), byte(OpSetLocal), 2, byte(OpReturn)), "local 2 out of range"},
+		{"jump into an instruction", encoded(t, byte(OpJump), 0, 1, byte(OpConstant), 0, 0, byte(OpReturn)), "does not land on an instruction"},
+		{"jump past the end", encoded(t, byte(OpJump), 0, 9, byte(OpNil), byte(OpReturn)), "does not land on an instruction"},
+		{"loop before the start", encoded(t, byte(OpLoop), 0, 9, byte(OpReturn)), "does not land on an instruction"},
+		{"stack underflow", encoded(t, byte(OpPop), byte(OpReturn)), "OP_RETURN at 1 in <script> needs 1 values"},
+		{"too many arguments", encoded(t, byte(OpCall), 5, byte(OpReturn)), "OP_CALL at 0 in <script> needs 6 values"},
+		{"mismatched depths", encoded(t, byte(OpTrue), byte(OpJumpIfFalse), 0, 1, byte(OpNil), byte(OpReturn)), "stack depth 3 does not match 2"},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+		_, err := Decode(bytes.NewReader(test.Data))
+
+		if err == nil || !strings.Contains(err.Error(), test.Expected) {
+			t.Errorf("expected an error containing %q but got %v", test.Expected, err)
+		}
+	}
+
+	// No prefix of a valid file may decode or panic
+	for n := 0; n < len(data); n++ {
+		if _, err := Decode(bytes.NewReader(data[:n])); err == nil {
+			t.Errorf("expected a %d byte prefix to fail", n)
+		}
+	}
+}
+
+func TestDisassemble(t *testing.T) {
+	out := &bytes.Buffer{}
+	Disassemble(out, sample())
+
+	expected := `== <script> ==
+0000    1 OP_CONSTANT           0 1.5
+0003    2 OP_CLOSURE            1 <fn inner>
+0006    |                     local 1
+0008    | OP_DEFINE_GLOBAL      2 "name"
+0011    3 OP_CONSTANT           3 -42
+0014    | OP_POP
+0015    4 OP_NIL
+0016    | OP_RETURN
+
+== <fn inner> ==
+0000    2 OP_GET_UPVALUE        0
+0002    3 OP_RETURN
+`
+
+	if out.String() != expected {
+		t.Errorf("expected\n%s\nbut got\n%s", expected, out.String())
+	}
+}
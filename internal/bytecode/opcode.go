@@ -0,0 +1,146 @@
+package bytecode
+
+// OpCode is a single virtual machine instruction, operands follow it in the chunk
+type OpCode byte
+
+const (
+	// OpConstant pushes the constant at a two byte index
+	OpConstant OpCode = iota
+	OpNil
+	OpTrue
+	OpFalse
+	OpPop
+
+	// OpGetLocal and OpSetLocal address a one byte stack slot in the current frame
+	OpGetLocal
+	OpSetLocal
+
+	// Global instructions name their variable with a two byte constant index
+	OpGetGlobal
+	OpDefineGlobal
+	OpSetGlobal
+
+	// OpGetUpvalue and OpSetUpvalue address a one byte upvalue of the running closure
+	OpGetUpvalue
+	OpSetUpvalue
+
+	// Property instructions name their property with a two byte constant index
+	OpGetProperty
+	OpSetProperty
+	OpGetSuper
+
+	OpGetIndex
+	OpSetIndex
+	OpEqual
+	OpGreater
+	OpGreaterEqual
+	OpLess
+	OpLessEqual
+	OpAdd
+	OpSubtract
+	OpMultiply
+	OpDivide
+	OpModulo
+	OpBitAnd
+	OpBitOr
+	OpBitXor
+	OpShiftLeft
+	OpShiftRight
+	OpNot
+	OpNegate
+	OpPrint
+
+	// Jumps carry a two byte unsigned offset, OpLoop jumps backwards
+	OpJump
+	OpJumpIfFalse
+	OpLoop
+
+	// OpCall carries a one byte argument count
+	OpCall
+
+	// OpClosure names a function constant, then lists an is-local byte and an index byte for
+	// each upvalue the function captures
+	OpClosure
+	OpCloseUpvalue
+	OpReturn
+
+	// OpClass and OpMethod name their class or method with a two byte constant index
+	OpClass
+	OpInherit
+	OpMethod
+
+	// OpList and OpMap carry a two byte element or entry count
+	OpList
+	OpMap
+
+	// OpImport names the module path with a two byte constant index
+	OpImport
+
+	// OpTry installs an error handler at a two byte forward offset until the matching OpEndTry,
+	// OpThrow raises the value on top of the stack
+	OpTry
+	OpEndTry
+	OpThrow
+)
+
+var opNames = map[OpCode]string{
+	OpConstant:     "OP_CONSTANT",
+	OpNil:          "OP_NIL",
+	OpTrue:         "OP_TRUE",
+	OpFalse:        "OP_FALSE",
+	OpPop:          "OP_POP",
+	OpGetLocal:     "OP_GET_LOCAL",
+	OpSetLocal:     "OP_SET_LOCAL",
+	OpGetGlobal:    "OP_GET_GLOBAL",
+	OpDefineGlobal: "OP_DEFINE_GLOBAL",
+	OpSetGlobal:    "OP_SET_GLOBAL",
+	OpGetUpvalue:   "OP_GET_UPVALUE",
+	OpSetUpvalue:   "OP_SET_UPVALUE",
+	OpGetProperty:  "OP_GET_PROPERTY",
+	OpSetProperty:  "OP_SET_PROPERTY",
+	OpGetSuper:     "OP_GET_SUPER",
+	OpGetIndex:     "OP_GET_INDEX",
+	OpSetIndex:     "OP_SET_INDEX",
+	OpEqual:        "OP_EQUAL",
+	OpGreater:      "OP_GREATER",
+	OpGreaterEqual: "OP_GREATER_EQUAL",
+	OpLess:         "OP_LESS",
+	OpLessEqual:    "OP_LESS_EQUAL",
+	OpAdd:          "OP_ADD",
+	OpSubtract:     "OP_SUBTRACT",
+	OpMultiply:     "OP_MULTIPLY",
+	OpDivide:       "OP_DIVIDE",
+	OpModulo:       "OP_MODULO",
+	OpBitAnd:       "OP_BIT_AND",
+	OpBitOr:        "OP_BIT_OR",
+	OpBitXor:       "OP_BIT_XOR",
+	OpShiftLeft:    "OP_SHIFT_LEFT",
+	OpShiftRight:   "OP_SHIFT_RIGHT",
+	OpNot:          "OP_NOT",
+	OpNegate:       "OP_NEGATE",
+	OpPrint:        "OP_PRINT",
+	OpJump:         "OP_JUMP",
+	OpJumpIfFalse:  "OP_JUMP_IF_FALSE",
+	OpLoop:         "OP_LOOP",
+	OpCall:         "OP_CALL",
+	OpClosure:      "OP_CLOSURE",
+	OpCloseUpvalue: "OP_CLOSE_UPVALUE",
+	OpReturn:       "OP_RETURN",
+	OpClass:        "OP_CLASS",
+	OpInherit:      "OP_INHERIT",
+	OpMethod:       "OP_METHOD",
+	OpList:         "OP_LIST",
+	OpMap:          "OP_MAP",
+	OpImport:       "OP_IMPORT",
+	OpTry:          "OP_TRY",
+	OpEndTry:       "OP_END_TRY",
+	OpThrow:        "OP_THROW",
+}
+
+func (o OpCode) String() string {
+	if name, ok := opNames[o]; ok {
+		return name
+	}
+
+	return "OP_UNKNOWN"
+}
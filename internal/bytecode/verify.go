@@ -0,0 +1,248 @@
+package bytecode
+
+// verify checks that every instruction of fn and its nested functions is well formed, so a
+// corrupt file is rejected before the virtual machine trusts it. Besides the operands of each
+// instruction it follows every path through the code to check that jumps land on instructions,
+// the stack never runs dry and locals are only read from slots that hold a value.
+func verify(fn *Function) error {
+	starts, err := instructions(fn)
+
+	if err != nil {
+		return err
+	}
+
+	if err := checkStack(fn, starts); err != nil {
+		return err
+	}
+
+	for _, constant := range fn.Chunk.Constants {
+		if nested, ok := constant.(*Function); ok {
+			if err := verify(nested); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// instructions checks the operands of each instruction on its own and returns where every
+// instruction starts
+func instructions(fn *Function) (map[int]bool, error) {
+	code, constants := fn.Chunk.Code, fn.Chunk.Constants
+	starts := make(map[int]bool)
+
+	last := OpCode(0)
+	for offset := 0; offset < len(code); {
+		op := OpCode(code[offset])
+		last = op
+		starts[offset] = true
+
+		if _, ok := opNames[op]; !ok {
+			return nil, newObjectError("unknown instruction %d at %d in %s", op, offset, fn)
+		}
+
+		size := instructionSize(op)
+		if offset+size > len(code) {
+			return nil, newObjectError("truncated %s at %d in %s", op, offset, fn)
+		}
+
+		switch op {
+		case OpConstant, OpGetGlobal, OpDefineGlobal, OpSetGlobal, OpGetProperty, OpSetProperty,
+			OpGetSuper, OpClass, OpMethod, OpImport, OpClosure:
+			index := short(code, offset+1)
+
+			if index >= len(constants) {
+				return nil, newObjectError("constant %d out of range at %d in %s", index, offset, fn)
+			}
+
+			if _, isString := constants[index].(string); op != OpConstant && op != OpClosure && !isString {
+				return nil, newObjectError("%s at %d in %s expects a name", op, offset, fn)
+			}
+
+			if op == OpClosure {
+				nested, ok := constants[index].(*Function)
+
+				if !ok {
+					return nil, newObjectError("%s at %d in %s expects a function", op, offset, fn)
+				}
+
+				size += 2 * nested.UpvalueCount
+				if offset+size > len(code) {
+					return nil, newObjectError("truncated %s at %d in %s", op, offset, fn)
+				}
+
+				for at := offset + 3; at < offset+size; at += 2 {
+					isLocal, index := code[at], int(code[at+1])
+
+					if isLocal > 1 || (isLocal == 0 && index >= fn.UpvalueCount) {
+						return nil, newObjectError("captured upvalue %d out of range at %d in %s", index, offset, fn)
+					}
+				}
+			}
+		case OpGetUpvalue, OpSetUpvalue:
+			if int(code[offset+1]) >= fn.UpvalueCount {
+				return nil, newObjectError("upvalue %d out of range at %d in %s", code[offset+1], offset, fn)
+			}
+		}
+
+		offset += size
+	}
+
+	// Every function returns explicitly, the machine would otherwise run off the end
+	if last != OpReturn {
+		return nil, newObjectError("%s does not end in a return", fn)
+	}
+
+	return starts, nil
+}
+
+// checkStack follows every path from the start of fn, and from each error handler, keeping
+// count of the values in the frame. The frame starts with the callee and its arguments. Each
+// instruction must find the values it takes, locals must be below the top and paths meeting at
+// an instruction must agree on the count, so slots mean the same thing whichever way it was
+// reached.
+func checkStack(fn *Function, starts map[int]bool) error {
+	code := fn.Chunk.Code
+	depths := make(map[int]int)
+	pending := []int{0}
+	depths[0] = 1 + fn.Arity
+
+	// reach records the count on arriving at target from the instruction at offset
+	reach := func(offset, target, depth int) error {
+		if target < 0 || target >= len(code) || !starts[target] {
+			return newObjectError("jump to %d at %d in %s does not land on an instruction", target, offset, fn)
+		}
+
+		if known, ok := depths[target]; ok {
+			if known != depth {
+				return newObjectError("stack depth %d does not match %d at %d in %s", depth, known, target, fn)
+			}
+
+			return nil
+		}
+
+		depths[target] = depth
+		pending = append(pending, target)
+		return nil
+	}
+
+	for len(pending) > 0 {
+		offset := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		depth := depths[offset]
+		op := OpCode(code[offset])
+		pops, pushes := stackEffect(code, offset)
+
+		if depth < pops {
+			return newObjectError("%s at %d in %s needs %d values but the stack holds %d", op, offset, fn, pops, depth)
+		}
+
+		switch op {
+		case OpGetLocal, OpSetLocal:
+			if int(code[offset+1]) >= depth {
+				return newObjectError("local %d out of range at %d in %s", code[offset+1], offset, fn)
+			}
+		case OpClosure:
+			for at := offset + 3; at < offset+closureSize(fn, offset); at += 2 {
+				if code[at] == 1 && int(code[at+1]) >= depth {
+					return newObjectError("captured local %d out of range at %d in %s", code[at+1], offset, fn)
+				}
+			}
+		}
+
+		after := depth - pops + pushes
+		next := offset + instructionSize(op)
+		if op == OpClosure {
+			next = offset + closureSize(fn, offset)
+		}
+
+		switch op {
+		case OpReturn, OpThrow:
+			continue
+		case OpJump:
+			if err := reach(offset, next+short(code, offset+1), after); err != nil {
+				return err
+			}
+
+			continue
+		case OpLoop:
+			if err := reach(offset, next-short(code, offset+1), after); err != nil {
+				return err
+			}
+
+			continue
+		case OpJumpIfFalse:
+			if err := reach(offset, next+short(code, offset+1), after); err != nil {
+				return err
+			}
+		case OpTry:
+			// The handler starts with the stack as it was here plus the caught error
+			if err := reach(offset, next+short(code, offset+1), after+1); err != nil {
+				return err
+			}
+		}
+
+		if err := reach(offset, next, after); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stackEffect is how many values the instruction at offset takes off of the stack and how many
+// it leaves in their place
+func stackEffect(code []byte, offset int) (int, int) {
+	switch op := OpCode(code[offset]); op {
+	case OpConstant, OpNil, OpTrue, OpFalse, OpGetLocal, OpGetGlobal, OpGetUpvalue, OpClosure,
+		OpClass, OpImport:
+		return 0, 1
+	case OpPop, OpDefineGlobal, OpPrint, OpCloseUpvalue, OpThrow, OpReturn:
+		return 1, 0
+	case OpSetLocal, OpSetGlobal, OpSetUpvalue, OpGetProperty, OpNot, OpNegate, OpJumpIfFalse:
+		return 1, 1
+	case OpSetProperty, OpGetSuper, OpGetIndex, OpEqual, OpGreater, OpGreaterEqual, OpLess,
+		OpLessEqual, OpAdd, OpSubtract, OpMultiply, OpDivide, OpModulo, OpBitAnd, OpBitOr, OpBitXor,
+		OpShiftLeft, OpShiftRight:
+		return 2, 1
+	case OpSetIndex:
+		return 3, 1
+	case OpInherit, OpMethod:
+		return 2, 1
+	case OpCall:
+		return int(code[offset+1]) + 1, 1
+	case OpList:
+		return short(code, offset+1), 1
+	case OpMap:
+		return 2 * short(code, offset+1), 1
+	}
+
+	// Jumps, loops and the try instructions leave the stack alone
+	return 0, 0
+}
+
+// closureSize is the length of the closure instruction at offset with its upvalue pairs
+func closureSize(fn *Function, offset int) int {
+	nested := fn.Chunk.Constants[short(fn.Chunk.Code, offset+1)].(*Function)
+	return instructionSize(OpClosure) + 2*nested.UpvalueCount
+}
+
+func short(code []byte, at int) int {
+	return int(code[at])<<8 | int(code[at+1])
+}
+
+// instructionSize is the length of an instruction and its operands, closures also carry
+// two bytes per upvalue
+func instructionSize(op OpCode) int {
+	switch op {
+	case OpGetLocal, OpSetLocal, OpGetUpvalue, OpSetUpvalue, OpCall:
+		return 2
+	case OpConstant, OpGetGlobal, OpDefineGlobal, OpSetGlobal, OpGetProperty, OpSetProperty,
+		OpGetSuper, OpClass, OpMethod, OpImport, OpJump, OpJumpIfFalse, OpTry, OpLoop, OpClosure, OpList, OpMap:
+		return 3
+	}
+
+	return 1
+}
@@ -0,0 +1,959 @@
+package compiler
+
+import (
+	"github.com/jparr721/obsidian/internal/bytecode"
+	"github.com/jparr721/obsidian/internal/expression"
+	"github.com/jparr721/obsidian/internal/statement"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+const (
+	maxLocals    = 256
+	maxUpvalues  = 256
+	maxConstants = 1 << 16
+	maxJump      = 1<<16 - 1
+)
+
+type functionKind int
+
+const (
+	kindScript functionKind = iota
+	kindFunction
+	kindMethod
+	kindInitializer
+)
+
+// local is a variable living in a stack slot of the function being compiled
+type local struct {
+	name string
+
+	// depth is -1 between the declaration and the end of the initializer
+	depth    int
+	captured bool
+}
+
+type upvalue struct {
+	index   byte
+	isLocal bool
+}
+
+// loop tracks the scope and try nesting a loop was entered at, along with the breaks waiting
+// for its end and the continues waiting for its increment
+type loop struct {
+	label     string
+	depth     int
+	tries     int
+	breaks    []int
+	continues []int
+}
+
+// try is a region with an error handler installed. Returns and breaks leaving it remove the
+// handler and run the finally clause, if there is one, on their way out.
+type try struct {
+	finally []statement.Statement
+}
+
+// function is the compiler state of a single function body, enclosing points at the body it
+// is nested in
+type function struct {
+	enclosing *function
+	function  *bytecode.Function
+	kind      functionKind
+	locals    []local
+	upvalues  []upvalue
+	depth     int
+	loops     []*loop
+	tries     []*try
+
+	// constants finds the slot of a number or string already in the chunk, equal ones share it
+	constants map[interface{}]int
+
+	// A limit is reported the first time the function passes it, not once per excess
+	tooManyConstants bool
+	tooManyLocals    bool
+}
+
+// Compiler turns a resolved syntax tree into bytecode for the virtual machine
+type Compiler struct {
+	current *function
+
+	// token is the last token seen, it supplies positions to nodes without one
+	token  tokens.Token
+	errors []*CompileError
+}
+
+func NewCompiler() *Compiler {
+	return &Compiler{errors: make([]*CompileError, 0)}
+}
+
+// Compile returns the top level script function, programs should pass the resolver first
+func (c *Compiler) Compile(statements []statement.Statement) (*bytecode.Function, []*CompileError) {
+	c.begin(kindScript, "")
+	c.statements(statements)
+	script, _ := c.end()
+
+	return script, c.errors
+}
+
+func (c *Compiler) error(message string) {
+	c.errors = append(c.errors, newCompileError(c.token, message))
+}
+
+func (c *Compiler) at(token tokens.Token) {
+	c.token = token
+}
+
+func (c *Compiler) chunk() *bytecode.Chunk {
+	return c.current.function.Chunk
+}
+
+func (c *Compiler) emit(code ...byte) {
+	for _, b := range code {
+		c.chunk().WriteSpan(b, c.token.Line, bytecode.Span{Column: c.token.Column, Length: c.token.Length})
+	}
+}
+
+func (c *Compiler) emitOp(op bytecode.OpCode, operands ...byte) {
+	c.emit(byte(op))
+	c.emit(operands...)
+}
+
+func (c *Compiler) emitShort(op bytecode.OpCode, operand int) {
+	c.emit(byte(op), byte(operand>>8), byte(operand))
+}
+
+func (c *Compiler) constant(value interface{}) int {
+	shared := false
+	switch value.(type) {
+	case int64, float64, string:
+		shared = true
+	}
+
+	if index, ok := c.current.constants[value]; shared && ok {
+		return index
+	}
+
+	if len(c.chunk().Constants) >= maxConstants {
+		if !c.current.tooManyConstants {
+			c.current.tooManyConstants = true
+			c.error("Too many constants in one chunk.")
+		}
+
+		return 0
+	}
+
+	// Shared slots are looked up in the map, searching the pool would make each constant cost
+	// the whole pool
+	index := c.chunk().AddConstant(value)
+
+	if shared {
+		c.current.constants[value] = index
+	}
+
+	return index
+}
+
+func (c *Compiler) emitConstant(value interface{}) {
+	c.emitShort(bytecode.OpConstant, c.constant(value))
+}
+
+// emitJump writes a jump with a placeholder offset and returns where to patch it
+func (c *Compiler) emitJump(op bytecode.OpCode) int {
+	c.emitShort(op, maxJump)
+	return len(c.chunk().Code) - 2
+}
+
+// patchJump points a jump written by emitJump at the next instruction
+func (c *Compiler) patchJump(offset int) {
+	jump := len(c.chunk().Code) - offset - 2
+
+	if jump > maxJump {
+		c.error("Too much code to jump over.")
+	}
+
+	c.chunk().Code[offset] = byte(jump >> 8)
+	c.chunk().Code[offset+1] = byte(jump)
+}
+
+func (c *Compiler) emitLoop(start int) {
+	offset := len(c.chunk().Code) - start + 3
+
+	if offset > maxJump {
+		c.error("Loop body too large.")
+	}
+
+	c.emitShort(bytecode.OpLoop, offset)
+}
+
+// emitReturn returns nil, or the instance when leaving an initializer
+func (c *Compiler) emitReturn() {
+	if c.current.kind == kindInitializer {
+		c.emitOp(bytecode.OpGetLocal, 0)
+	} else {
+		c.emitOp(bytecode.OpNil)
+	}
+
+	c.emitOp(bytecode.OpReturn)
+}
+
+func (c *Compiler) begin(kind functionKind, name string) {
+	// Slot zero holds the callee, or the receiver inside of methods
+	slot := ""
+	if kind == kindMethod || kind == kindInitializer {
+		slot = "this"
+	}
+
+	c.current = &function{
+		enclosing: c.current,
+		function:  bytecode.NewFunction(name),
+		kind:      kind,
+		locals:    []local{{slot, 0, false}},
+		upvalues:  make([]upvalue, 0),
+		loops:     make([]*loop, 0),
+		constants: make(map[interface{}]int),
+	}
+}
+
+func (c *Compiler) end() (*bytecode.Function, []upvalue) {
+	c.emitReturn()
+
+	compiled, upvalues := c.current.function, c.current.upvalues
+	compiled.UpvalueCount = len(upvalues)
+	c.current = c.current.enclosing
+
+	return compiled, upvalues
+}
+
+func (c *Compiler) beginScope() {
+	c.current.depth++
+}
+
+func (c *Compiler) endScope() {
+	c.current.depth--
+	c.popLocals(c.current.depth)
+	c.current.locals = c.current.locals[:c.firstLocalAbove(c.current.depth)]
+}
+
+// popLocals emits the pops for every local deeper than depth, it leaves the locals declared
+func (c *Compiler) popLocals(depth int) {
+	for n := len(c.current.locals) - 1; n >= c.firstLocalAbove(depth); n-- {
+		if c.current.locals[n].captured {
+			c.emitOp(bytecode.OpCloseUpvalue)
+		} else {
+			c.emitOp(bytecode.OpPop)
+		}
+	}
+}
+
+func (c *Compiler) firstLocalAbove(depth int) int {
+	n := len(c.current.locals)
+	for n > 0 && c.current.locals[n-1].depth > depth {
+		n--
+	}
+
+	return n
+}
+
+func (c *Compiler) addLocal(name string) {
+	if len(c.current.locals) == maxLocals {
+		if !c.current.tooManyLocals {
+			c.current.tooManyLocals = true
+			c.error("Too many local variables in function.")
+		}
+
+		return
+	}
+
+	c.current.locals = append(c.current.locals, local{name, -1, false})
+}
+
+// declare reserves a stack slot for a local, globals are created by define instead
+func (c *Compiler) declare(name tokens.Token) {
+	if c.current.depth == 0 {
+		return
+	}
+
+	c.addLocal(name.Lexeme)
+}
+
+func (c *Compiler) markInitialized() {
+	if c.current.depth == 0 {
+		return
+	}
+
+	c.current.locals[len(c.current.locals)-1].depth = c.current.depth
+}
+
+// define makes a declared variable visible, globals take the value on top of the stack
+func (c *Compiler) define(name tokens.Token) {
+	if c.current.depth > 0 {
+		c.markInitialized()
+		return
+	}
+
+	c.emitShort(bytecode.OpDefineGlobal, c.constant(name.Lexeme))
+}
+
+func resolveLocal(f *function, name string) int {
+	for n := len(f.locals) - 1; n >= 0; n-- {
+		if f.locals[n].name == name {
+			return n
+		}
+	}
+
+	return -1
+}
+
+func (c *Compiler) resolveUpvalue(f *function, name string) int {
+	if f.enclosing == nil {
+		return -1
+	}
+
+	if slot := resolveLocal(f.enclosing, name); slot != -1 {
+		f.enclosing.locals[slot].captured = true
+		return c.addUpvalue(f, byte(slot), true)
+	}
+
+	if index := c.resolveUpvalue(f.enclosing, name); index != -1 {
+		return c.addUpvalue(f, byte(index), false)
+	}
+
+	return -1
+}
+
+func (c *Compiler) addUpvalue(f *function, index byte, isLocal bool) int {
+	for n, existing := range f.upvalues {
+		if existing.index == index && existing.isLocal == isLocal {
+			return n
+		}
+	}
+
+	if len(f.upvalues) == maxUpvalues {
+		c.error("Too many closure variables in function.")
+		return 0
+	}
+
+	f.upvalues = append(f.upvalues, upvalue{index, isLocal})
+	return len(f.upvalues) - 1
+}
+
+func (c *Compiler) getVariable(name string) {
+	if slot := resolveLocal(c.current, name); slot != -1 {
+		c.emitOp(bytecode.OpGetLocal, byte(slot))
+	} else if index := c.resolveUpvalue(c.current, name); index != -1 {
+		c.emitOp(bytecode.OpGetUpvalue, byte(index))
+	} else {
+		c.emitShort(bytecode.OpGetGlobal, c.constant(name))
+	}
+}
+
+func (c *Compiler) setVariable(name string) {
+	if slot := resolveLocal(c.current, name); slot != -1 {
+		c.emitOp(bytecode.OpSetLocal, byte(slot))
+	} else if index := c.resolveUpvalue(c.current, name); index != -1 {
+		c.emitOp(bytecode.OpSetUpvalue, byte(index))
+	} else {
+		c.emitShort(bytecode.OpSetGlobal, c.constant(name))
+	}
+}
+
+func (c *Compiler) statements(statements []statement.Statement) {
+	for _, s := range statements {
+		s.Accept(c)
+	}
+}
+
+func (c *Compiler) expression(e expression.Expression) {
+	e.Accept(c)
+}
+
+// function compiles a function body and emits the closure that captures its upvalues
+func (c *Compiler) function(kind functionKind, name string, arguments []tokens.Token, body []statement.Statement) {
+	declaration := c.token
+	c.begin(kind, name)
+	c.beginScope()
+
+	for _, argument := range arguments {
+		c.at(argument)
+		c.declare(argument)
+		c.markInitialized()
+	}
+
+	c.current.function.Arity = len(arguments)
+	c.statements(body)
+
+	compiled, upvalues := c.end()
+
+	// The closure is created where the function is declared, not where its body ends
+	c.at(declaration)
+	c.emitShort(bytecode.OpClosure, c.constant(compiled))
+
+	for _, up := range upvalues {
+		isLocal := byte(0)
+		if up.isLocal {
+			isLocal = 1
+		}
+
+		c.emit(isLocal, up.index)
+	}
+}
+
+func (c *Compiler) VisitExpressionStatement(s *statement.ExpressionStatement) (interface{}, error) {
+	c.expression(s.Expression)
+	c.emitOp(bytecode.OpPop)
+	return nil, nil
+}
+
+func (c *Compiler) VisitPrintStatement(s *statement.PrintStatement) (interface{}, error) {
+	c.expression(s.Expression)
+	c.emitOp(bytecode.OpPrint)
+	return nil, nil
+}
+
+func (c *Compiler) VisitVariableStatement(s *statement.VariableStatement) (interface{}, error) {
+	c.at(s.Name)
+	c.declare(s.Name)
+
+	if s.Initializer != nil {
+		c.expression(s.Initializer)
+	} else {
+		c.emitOp(bytecode.OpNil)
+	}
+
+	c.at(s.Name)
+	c.define(s.Name)
+	return nil, nil
+}
+
+func (c *Compiler) VisitBlockStatement(s *statement.BlockStatement) (interface{}, error) {
+	c.beginScope()
+	c.statements(s.Statements)
+	c.endScope()
+	return nil, nil
+}
+
+func (c *Compiler) VisitIfStatement(s *statement.IfStatement) (interface{}, error) {
+	c.expression(s.Condition)
+
+	thenJump := c.emitJump(bytecode.OpJumpIfFalse)
+	c.emitOp(bytecode.OpPop)
+	s.ThenBranch.Accept(c)
+
+	elseJump := c.emitJump(bytecode.OpJump)
+	c.patchJump(thenJump)
+	c.emitOp(bytecode.OpPop)
+
+	if s.ElseBranch != nil {
+		s.ElseBranch.Accept(c)
+	}
+
+	c.patchJump(elseJump)
+	return nil, nil
+}
+
+func (c *Compiler) VisitWhileStatement(s *statement.WhileStatement) (interface{}, error) {
+	start := len(c.chunk().Code)
+	c.expression(s.Condition)
+
+	exitJump := c.emitJump(bytecode.OpJumpIfFalse)
+	c.emitOp(bytecode.OpPop)
+
+	l := &loop{s.Label.Lexeme, c.current.depth, len(c.current.tries), make([]int, 0), make([]int, 0)}
+	c.current.loops = append(c.current.loops, l)
+	s.Body.Accept(c)
+	c.current.loops = c.current.loops[:len(c.current.loops)-1]
+
+	for _, jump := range l.continues {
+		c.patchJump(jump)
+	}
+
+	if s.Increment != nil {
+		c.expression(s.Increment)
+		c.emitOp(bytecode.OpPop)
+	}
+
+	c.emitLoop(start)
+	c.patchJump(exitJump)
+	c.emitOp(bytecode.OpPop)
+
+	// Breaks have already popped the condition, they land after the exit's pop
+	for _, jump := range l.breaks {
+		c.patchJump(jump)
+	}
+
+	return nil, nil
+}
+
+func (c *Compiler) VisitBreakStatement(s *statement.BreakStatement) (interface{}, error) {
+	l := c.leaveLoop(s.Instance, s.Label)
+	l.breaks = append(l.breaks, c.emitJump(bytecode.OpJump))
+	return nil, nil
+}
+
+func (c *Compiler) VisitContinueStatement(s *statement.ContinueStatement) (interface{}, error) {
+	l := c.leaveLoop(s.Instance, s.Label)
+	l.continues = append(l.continues, c.emitJump(bytecode.OpJump))
+	return nil, nil
+}
+
+// leaveLoop finds the loop a break or continue is for, the innermost one unless it is labeled,
+// and emits what gets out of the body: finally clauses of tries in between and the pops of the
+// body's locals
+func (c *Compiler) leaveLoop(instance, label tokens.Token) *loop {
+	l := c.current.loops[len(c.current.loops)-1]
+
+	if label.Lexeme != "" {
+		for n := len(c.current.loops) - 1; n >= 0; n-- {
+			if c.current.loops[n].label == label.Lexeme {
+				l = c.current.loops[n]
+				break
+			}
+		}
+	}
+
+	c.at(instance)
+	c.unwindTries(l.tries)
+	c.at(instance)
+	c.popLocals(l.depth)
+	return l
+}
+
+func (c *Compiler) VisitFunctionStatement(s *statement.FunctionStatement) (interface{}, error) {
+	c.at(s.Name)
+	c.declare(s.Name)
+
+	// Functions may refer to themselves, so they are usable before their body is compiled
+	c.markInitialized()
+	c.function(kindFunction, s.Name.Lexeme, s.Arguments, s.Body)
+
+	c.at(s.Name)
+	c.define(s.Name)
+	return nil, nil
+}
+
+func (c *Compiler) VisitReturnStatement(s *statement.ReturnStatement) (interface{}, error) {
+	c.at(s.Keyword)
+
+	if s.Value == nil {
+		c.unwindTries(0)
+		c.at(s.Keyword)
+		c.emitReturn()
+		return nil, nil
+	}
+
+	c.expression(s.Value)
+
+	if len(c.current.tries) == 0 {
+		c.emitOp(bytecode.OpReturn)
+		return nil, nil
+	}
+
+	// The value waits in a slot of its own while finally clauses run above it
+	slot := c.hiddenLocal()
+	c.unwindTries(0)
+
+	c.at(s.Keyword)
+	c.emitOp(bytecode.OpGetLocal, byte(slot))
+	c.emitOp(bytecode.OpReturn)
+	c.dropHiddenLocal()
+	return nil, nil
+}
+
+func (c *Compiler) VisitThrowStatement(s *statement.ThrowStatement) (interface{}, error) {
+	c.expression(s.Value)
+	c.at(s.Keyword)
+	c.emitOp(bytecode.OpThrow)
+	return nil, nil
+}
+
+// VisitTryStatement installs a handler around the body. The machine removes it, pushes the
+// caught error and jumps to the catch clause when the body fails. Finally clauses are compiled
+// into every way out: the end of the body, the end of the catch clause and a path that rethrows
+// errors the catch clause didn't handle.
+func (c *Compiler) VisitTryStatement(s *statement.TryStatement) (interface{}, error) {
+	c.at(s.Keyword)
+	handler := c.emitJump(bytecode.OpTry)
+	c.protected(s.Body, s.Finally)
+
+	c.at(s.Keyword)
+	c.emitOp(bytecode.OpEndTry)
+	c.finally(s.Finally)
+	exits := []int{c.emitJump(bytecode.OpJump)}
+	c.patchJump(handler)
+
+	if s.Catch != nil {
+		rethrow := -1
+		if s.Finally != nil {
+			rethrow = c.emitJump(bytecode.OpTry)
+		}
+
+		// The caught error is already on the stack, it becomes the catch clause's first local
+		c.beginScope()
+		c.at(s.CatchName)
+		c.addLocal(s.CatchName.Lexeme)
+		c.markInitialized()
+
+		if s.Finally != nil {
+			c.protected(s.Catch, s.Finally)
+		} else {
+			c.statements(s.Catch)
+		}
+
+		c.endScope()
+
+		if s.Finally == nil {
+			c.patchJump(exits[0])
+			return nil, nil
+		}
+
+		c.at(s.Keyword)
+		c.emitOp(bytecode.OpEndTry)
+		c.finally(s.Finally)
+		exits = append(exits, c.emitJump(bytecode.OpJump))
+		c.patchJump(rethrow)
+	}
+
+	// Errors nothing caught run the finally clause and carry on outwards. An error raised in the
+	// catch clause sits above the error it caught, which was still on the stack.
+	if s.Catch != nil {
+		c.hiddenLocal()
+	}
+
+	slot := c.hiddenLocal()
+	c.finally(s.Finally)
+
+	c.at(s.Keyword)
+	c.emitOp(bytecode.OpGetLocal, byte(slot))
+	c.emitOp(bytecode.OpThrow)
+	c.dropHiddenLocal()
+
+	if s.Catch != nil {
+		c.dropHiddenLocal()
+	}
+
+	for _, exit := range exits {
+		c.patchJump(exit)
+	}
+
+	return nil, nil
+}
+
+// protected compiles statements in a scope of their own with a handler installed around them
+func (c *Compiler) protected(statements []statement.Statement, finally []statement.Statement) {
+	c.current.tries = append(c.current.tries, &try{finally})
+
+	c.beginScope()
+	c.statements(statements)
+	c.endScope()
+
+	c.current.tries = c.current.tries[:len(c.current.tries)-1]
+}
+
+// finally compiles a finally clause in a scope of its own, a missing clause compiles to nothing
+func (c *Compiler) finally(statements []statement.Statement) {
+	if statements == nil {
+		return
+	}
+
+	c.beginScope()
+	c.statements(statements)
+	c.endScope()
+}
+
+// unwindTries removes the handlers of every try nested deeper than count, innermost first,
+// running their finally clauses. A finally clause only sees the tries around its own.
+func (c *Compiler) unwindTries(count int) {
+	tries := c.current.tries
+
+	for n := len(tries) - 1; n >= count; n-- {
+		c.emitOp(bytecode.OpEndTry)
+
+		c.current.tries = tries[:n]
+		c.finally(tries[n].finally)
+	}
+
+	c.current.tries = tries
+}
+
+// hiddenLocal gives the value on top of the stack a slot no name can reach and returns it
+func (c *Compiler) hiddenLocal() int {
+	c.beginScope()
+	c.addLocal("")
+	c.markInitialized()
+	return len(c.current.locals) - 1
+}
+
+// dropHiddenLocal forgets the slot from hiddenLocal once control can no longer reach its end,
+// so nothing is emitted to pop it
+func (c *Compiler) dropHiddenLocal() {
+	c.current.depth--
+	c.current.locals = c.current.locals[:c.firstLocalAbove(c.current.depth)]
+}
+
+func (c *Compiler) VisitClassStatement(s *statement.ClassStatement) (interface{}, error) {
+	c.at(s.Name)
+	name := c.constant(s.Name.Lexeme)
+
+	c.declare(s.Name)
+	c.emitShort(bytecode.OpClass, name)
+	c.define(s.Name)
+
+	// Methods of a subclass close over a scope holding "super"
+	if s.Superclass != nil {
+		c.at(s.Superclass.Name)
+		c.getVariable(s.Superclass.Name.Lexeme)
+
+		c.beginScope()
+		c.addLocal("super")
+		c.markInitialized()
+
+		c.getVariable(s.Name.Lexeme)
+		c.emitOp(bytecode.OpInherit)
+	}
+
+	c.getVariable(s.Name.Lexeme)
+
+	for _, method := range s.Methods {
+		c.at(method.Name)
+
+		kind := kindMethod
+		if method.Name.Lexeme == "init" {
+			kind = kindInitializer
+		}
+
+		c.function(kind, method.Name.Lexeme, method.Arguments, method.Body)
+		c.emitShort(bytecode.OpMethod, c.constant(method.Name.Lexeme))
+	}
+
+	c.emitOp(bytecode.OpPop)
+
+	if s.Superclass != nil {
+		c.endScope()
+	}
+
+	return nil, nil
+}
+
+func (c *Compiler) VisitImportStatement(s *statement.ImportStatement) (interface{}, error) {
+	c.at(s.Keyword)
+	c.emitShort(bytecode.OpImport, c.constant(s.Path.Literal))
+
+	c.at(s.Name)
+	c.declare(s.Name)
+	c.define(s.Name)
+	return nil, nil
+}
+
+func (c *Compiler) VisitBinaryExpression(e *expression.BinaryExpression) (interface{}, error) {
+	c.expression(e.Left)
+	c.expression(e.Right)
+	c.at(e.Operator)
+
+	switch e.Operator.Variant {
+	case tokens.TokenPlus:
+		c.emitOp(bytecode.OpAdd)
+	case tokens.TokenMinus:
+		c.emitOp(bytecode.OpSubtract)
+	case tokens.TokenStar:
+		c.emitOp(bytecode.OpMultiply)
+	case tokens.TokenSlash:
+		c.emitOp(bytecode.OpDivide)
+	case tokens.TokenModulo:
+		c.emitOp(bytecode.OpModulo)
+	case tokens.TokenAmpersand:
+		c.emitOp(bytecode.OpBitAnd)
+	case tokens.TokenPipe:
+		c.emitOp(bytecode.OpBitOr)
+	case tokens.TokenCaret:
+		c.emitOp(bytecode.OpBitXor)
+	case tokens.TokenLessLess:
+		c.emitOp(bytecode.OpShiftLeft)
+	case tokens.TokenGreaterGreater:
+		c.emitOp(bytecode.OpShiftRight)
+	case tokens.TokenGreater:
+		c.emitOp(bytecode.OpGreater)
+	case tokens.TokenGreaterEqual:
+		c.emitOp(bytecode.OpGreaterEqual)
+	case tokens.TokenLess:
+		c.emitOp(bytecode.OpLess)
+	case tokens.TokenLessEqual:
+		c.emitOp(bytecode.OpLessEqual)
+	case tokens.TokenEqualEqual:
+		c.emitOp(bytecode.OpEqual)
+	case tokens.TokenBangEqual:
+		c.emitOp(bytecode.OpEqual)
+		c.emitOp(bytecode.OpNot)
+	}
+
+	return nil, nil
+}
+
+func (c *Compiler) VisitGroupingExpression(e *expression.GroupingExpression) (interface{}, error) {
+	c.expression(e.Expression.(expression.Expression))
+	return nil, nil
+}
+
+func (c *Compiler) VisitLiteralExpression(e *expression.LiteralExpression) (interface{}, error) {
+	switch e.Value {
+	case nil:
+		c.emitOp(bytecode.OpNil)
+	case true:
+		c.emitOp(bytecode.OpTrue)
+	case false:
+		c.emitOp(bytecode.OpFalse)
+	default:
+		c.emitConstant(e.Value)
+	}
+
+	return nil, nil
+}
+
+func (c *Compiler) VisitUnaryExpression(e *expression.UnaryExpression) (interface{}, error) {
+	c.expression(e.Right.(expression.Expression))
+	c.at(e.Operator)
+
+	switch e.Operator.Variant {
+	case tokens.TokenMinus:
+		c.emitOp(bytecode.OpNegate)
+	case tokens.TokenBang:
+		c.emitOp(bytecode.OpNot)
+	}
+
+	return nil, nil
+}
+
+func (c *Compiler) VisitVariableExpression(e *expression.VariableExpression) (interface{}, error) {
+	c.at(e.Name)
+	c.getVariable(e.Name.Lexeme)
+	return nil, nil
+}
+
+func (c *Compiler) VisitAssignExpression(e *expression.AssignExpression) (interface{}, error) {
+	c.expression(e.Value)
+	c.at(e.Name)
+	c.setVariable(e.Name.Lexeme)
+	return nil, nil
+}
+
+func (c *Compiler) VisitLogicalExpression(e *expression.LogicalExpression) (interface{}, error) {
+	c.expression(e.Left)
+	c.at(e.Operator)
+
+	if e.Operator.Variant == tokens.TokenOr {
+		elseJump := c.emitJump(bytecode.OpJumpIfFalse)
+		endJump := c.emitJump(bytecode.OpJump)
+
+		c.patchJump(elseJump)
+		c.emitOp(bytecode.OpPop)
+		c.expression(e.Right)
+		c.patchJump(endJump)
+		return nil, nil
+	}
+
+	endJump := c.emitJump(bytecode.OpJumpIfFalse)
+	c.emitOp(bytecode.OpPop)
+	c.expression(e.Right)
+	c.patchJump(endJump)
+	return nil, nil
+}
+
+func (c *Compiler) VisitCallExpression(e *expression.CallExpression) (interface{}, error) {
+	c.expression(e.Callee)
+
+	for _, argument := range e.Arguments {
+		c.expression(argument)
+	}
+
+	c.at(e.Paren)
+	c.emitOp(bytecode.OpCall, byte(len(e.Arguments)))
+	return nil, nil
+}
+
+func (c *Compiler) VisitGetExpression(e *expression.GetExpression) (interface{}, error) {
+	c.expression(e.Object)
+	c.at(e.Name)
+	c.emitShort(bytecode.OpGetProperty, c.constant(e.Name.Lexeme))
+	return nil, nil
+}
+
+func (c *Compiler) VisitSetExpression(e *expression.SetExpression) (interface{}, error) {
+	c.expression(e.Object)
+	c.expression(e.Value)
+	c.at(e.Name)
+	c.emitShort(bytecode.OpSetProperty, c.constant(e.Name.Lexeme))
+	return nil, nil
+}
+
+func (c *Compiler) VisitThisExpression(e *expression.ThisExpression) (interface{}, error) {
+	c.at(e.Keyword)
+	c.getVariable("this")
+	return nil, nil
+}
+
+func (c *Compiler) VisitSuperExpression(e *expression.SuperExpression) (interface{}, error) {
+	c.at(e.Keyword)
+	c.getVariable("this")
+	c.getVariable("super")
+
+	c.at(e.Method)
+	c.emitShort(bytecode.OpGetSuper, c.constant(e.Method.Lexeme))
+	return nil, nil
+}
+
+func (c *Compiler) VisitFunctionExpression(e *expression.FunctionExpression) (interface{}, error) {
+	c.at(e.Keyword)
+	c.function(kindFunction, "anonymous", e.Arguments, e.Body.([]statement.Statement))
+	return nil, nil
+}
+
+// VisitInterpolationExpression adds each part onto the leading string segment, adding anything
+// to a string appends its stringified value
+func (c *Compiler) VisitInterpolationExpression(e *expression.InterpolationExpression) (interface{}, error) {
+	c.expression(e.Parts[0])
+
+	for _, part := range e.Parts[1:] {
+		c.expression(part)
+		c.at(e.Start)
+		c.emitOp(bytecode.OpAdd)
+	}
+
+	return nil, nil
+}
+
+func (c *Compiler) VisitListExpression(e *expression.ListExpression) (interface{}, error) {
+	for _, element := range e.Elements {
+		c.expression(element)
+	}
+
+	c.at(e.Bracket)
+	c.emitShort(bytecode.OpList, len(e.Elements))
+	return nil, nil
+}
+
+func (c *Compiler) VisitIndexExpression(e *expression.IndexExpression) (interface{}, error) {
+	c.expression(e.Object)
+	c.expression(e.Index)
+	c.at(e.Bracket)
+	c.emitOp(bytecode.OpGetIndex)
+	return nil, nil
+}
+
+func (c *Compiler) VisitIndexSetExpression(e *expression.IndexSetExpression) (interface{}, error) {
+	c.expression(e.Object)
+	c.expression(e.Index)
+	c.expression(e.Value)
+	c.at(e.Bracket)
+	c.emitOp(bytecode.OpSetIndex)
+	return nil, nil
+}
+
+func (c *Compiler) VisitMapExpression(e *expression.MapExpression) (interface{}, error) {
+	for n := range e.Keys {
+		c.expression(e.Keys[n])
+		c.expression(e.Values[n])
+	}
+
+	c.at(e.Brace)
+	c.emitShort(bytecode.OpMap, len(e.Keys))
+	return nil, nil
+}
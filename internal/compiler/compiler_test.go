@@ -0,0 +1,82 @@
+package compiler
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jparr721/obsidian/internal/parser"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+type compileTest struct {
+	Name     string
+	Source   string
+	Expected []string
+}
+
+// compile returns the message of every error compiling src reports
+func compile(t *testing.T, src string) []string {
+	toks, tokErr := tokens.NewTokenizer(src).ScanTokens()
+	if tokErr != nil {
+		t.Fatalf("failed to tokenize test source: %v", tokErr)
+	}
+
+	statements, parseErrs := parser.NewParser(toks).Parse()
+	if len(parseErrs) > 0 {
+		t.Fatalf("failed to parse test source: %v", parseErrs)
+	}
+
+	_, errs := NewCompiler().Compile(statements)
+
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.message)
+	}
+
+	return messages
+}
+
+// repeated joins count copies of format, each given its index
+func repeated(format string, count int) string {
+	var b strings.Builder
+	for n := 0; n < count; n++ {
+		fmt.Fprintf(&b, format, n)
+	}
+
+	return b.String()
+}
+
+func TestLimits(t *testing.T) {
+	tests := []compileTest{
+		{
+			Name:     "Too many constants is reported once",
+			Source:   repeated("print %d.5;\n", maxConstants+5000),
+			Expected: []string{"Too many constants in one chunk."},
+		},
+		{
+			Name:     "Too many locals is reported once",
+			Source:   "fun f() {\n" + repeated("var v%d;\n", maxLocals+50) + "}",
+			Expected: []string{"Too many local variables in function."},
+		},
+		{
+			Name:     "Each function reports its own limit",
+			Source:   "fun f() {\n" + repeated("var v%d;\n", maxLocals) + "}\nfun g() {\n" + repeated("var v%d;\n", maxLocals) + "}",
+			Expected: []string{"Too many local variables in function.", "Too many local variables in function."},
+		},
+		{
+			Name:     "Programs within the limits compile",
+			Source:   "fun f() {\n" + repeated("var v%d;\n", maxLocals-1) + "}",
+			Expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+		errs := compile(t, test.Source)
+
+		if strings.Join(errs, "\n") != strings.Join(test.Expected, "\n") {
+			t.Errorf("%s: expected errors %q but got %d: %.200q", test.Name, test.Expected, len(errs), errs)
+		}
+	}
+}
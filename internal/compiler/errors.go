@@ -0,0 +1,27 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/jparr721/obsidian/internal/diagnostics"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+// CompileError represents a program that resolves but exceeds a limit of the bytecode format
+type CompileError struct {
+	token   tokens.Token
+	message string
+}
+
+func newCompileError(token tokens.Token, message string) *CompileError {
+	return &CompileError{token, message}
+}
+
+func (c *CompileError) Error() string {
+	return fmt.Sprintf("CompileError: [line %d] Error at '%s': %s", c.token.Line, c.token.Lexeme, c.message)
+}
+
+// Diagnostic points at the last token compiled before the limit was hit
+func (c *CompileError) Diagnostic() *diagnostics.Diagnostic {
+	return &diagnostics.Diagnostic{Code: diagnostics.CodeCompile, Message: c.message, Span: c.token.Span()}
+}
@@ -0,0 +1,210 @@
+package diagnostics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Codes name the stage of the pipeline that found a problem, they lead every rendered diagnostic
+const (
+	CodeToken          = "E0001"
+	CodeParse          = "E0002"
+	CodeResolve        = "E0003"
+	CodeCompile        = "E0004"
+	CodeRuntime        = "E0005"
+	CodeResolveWarning = "W0003"
+)
+
+// Severity separates problems that stop a program from ones it can run despite
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+
+	return "error"
+}
+
+// Span is a stretch of source text. Line and Column start at 1, Column and Length count runes.
+// A zero Line means the location is unknown.
+type Span struct {
+	File   string
+	Line   int
+	Column int
+	Length int
+}
+
+// Diagnostic is a problem found in a program along with where it happened
+type Diagnostic struct {
+	Severity Severity
+	Code     string
+	Message  string
+	Span     Span
+	Notes    []string
+
+	// Trace lists the calls that led to a runtime error, oldest first
+	Trace []Frame
+}
+
+// Frame is a function in a traceback and where in it execution was
+type Frame struct {
+	Name string
+	Span Span
+}
+
+// maxRepeats is how many times the same frame is listed in a row before the rest are counted,
+// so runaway recursion doesn't bury the error
+const maxRepeats = 3
+
+// Diagnosable is implemented by errors that can point at the source they came from
+type Diagnosable interface {
+	error
+	Diagnostic() *Diagnostic
+}
+
+// Sources holds the text of every file diagnostics may point into, keyed by file name
+type Sources map[string]string
+
+// Report renders err as a diagnostic when it is one and prints it as is otherwise
+func (s Sources) Report(w io.Writer, err error) {
+	if d, ok := err.(Diagnosable); ok {
+		s.Render(w, d.Diagnostic())
+		return
+	}
+
+	fmt.Fprintln(w, err)
+}
+
+// Render writes d rustc-style: a header with the code and message, the location, the offending
+// line with its span underlined and then any notes. The snippet is left out when the source of
+// the file is unknown. A traceback comes first, like Python prints one.
+func (s Sources) Render(w io.Writer, d *Diagnostic) {
+	if len(d.Trace) > 0 {
+		s.traceback(w, d.Trace)
+	}
+
+	fmt.Fprintf(w, "%s[%s]: %s\n", d.Severity, d.Code, d.Message)
+
+	span := d.Span
+	line, ok := s.line(span)
+	gutter := strings.Repeat(" ", len(strconv.Itoa(span.Line)))
+
+	if span.Line > 0 {
+		fmt.Fprintf(w, "%s--> %s\n", gutter, span)
+	}
+
+	if ok {
+		fmt.Fprintf(w, "%s |\n", gutter)
+		fmt.Fprintf(w, "%d | %s\n", span.Line, line)
+		fmt.Fprintf(w, "%s | %s\n", gutter, underline(line, span))
+	}
+
+	for _, note := range d.Notes {
+		fmt.Fprintf(w, "%s = note: %s\n", gutter, note)
+	}
+}
+
+// traceback writes each frame with the line it was running, innermost last
+func (s Sources) traceback(w io.Writer, trace []Frame) {
+	fmt.Fprintln(w, "Traceback (most recent call last):")
+
+	for n := 0; n < len(trace); {
+		frame := trace[n]
+
+		run := 1
+		for n+run < len(trace) && trace[n+run] == frame {
+			run++
+		}
+
+		shown := run
+		if shown > maxRepeats {
+			shown = maxRepeats
+		}
+
+		for ; shown > 0; shown-- {
+			fmt.Fprintf(w, "  %s in %s\n", frame.Span, frame.Name)
+
+			if line, ok := s.line(frame.Span); ok {
+				fmt.Fprintf(w, "    %s\n", strings.TrimSpace(line))
+			}
+		}
+
+		if run > maxRepeats {
+			fmt.Fprintf(w, "  [Previous frame repeated %d more times]\n", run-maxRepeats)
+		}
+
+		n += run
+	}
+}
+
+// String is the span's location as file:line:column, just line:column when there is no file
+func (s Span) String() string {
+	position := fmt.Sprintf("%d:%d", s.Line, s.Column)
+
+	if s.File == "" {
+		return position
+	}
+
+	return s.File + ":" + position
+}
+
+// line returns the source line span starts on without its line ending
+func (s Sources) line(span Span) (string, bool) {
+	src, ok := s[span.File]
+
+	if !ok || span.Line < 1 {
+		return "", false
+	}
+
+	lines := strings.Split(src, "\n")
+
+	if span.Line > len(lines) {
+		return "", false
+	}
+
+	return strings.TrimRight(lines[span.Line-1], "\r"), true
+}
+
+// underline places carets under the span, tabs before it are kept so the carets line up with
+// the text above them. Spans running past the end of the line, like multi-line strings, stop
+// at its end.
+func underline(line string, span Span) string {
+	var b strings.Builder
+
+	column := 1
+	for _, c := range line {
+		if column >= span.Column {
+			break
+		}
+
+		if c == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+
+		column++
+	}
+
+	// A span at the end of the line, like an unexpected end of file, still gets one caret
+	length := span.Length
+	if remaining := utf8.RuneCountInString(line) - column + 1; length > remaining {
+		length = remaining
+	}
+
+	if length < 1 {
+		length = 1
+	}
+
+	b.WriteString(strings.Repeat("^", length))
+	return b.String()
+}
@@ -0,0 +1,123 @@
+package diagnostics
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type renderTest struct {
+	Name       string
+	Diagnostic *Diagnostic
+	Expected   string
+}
+
+func TestRender(t *testing.T) {
+	sources := Sources{
+		"main.ob": "var a = 1;\nprint a + a + nil + a;\n\tprint \"héllo\" + nil;\nprint \"one\ntwo\";",
+		"":        "print x;",
+	}
+
+	tests := []renderTest{
+		{
+			Name:       "Caret under the failing operator",
+			Diagnostic: &Diagnostic{Code: CodeRuntime, Message: "Operands must be two numbers.", Span: Span{"main.ob", 2, 13, 1}},
+			Expected: "error[E0005]: Operands must be two numbers.\n" +
+				" --> main.ob:2:13\n" +
+				"  |\n" +
+				"2 | print a + a + nil + a;\n" +
+				"  |             ^\n",
+		},
+		{
+			Name:       "Tabs and wide runes before the span",
+			Diagnostic: &Diagnostic{Code: CodeRuntime, Message: "Bad.", Span: Span{"main.ob", 3, 16, 1}},
+			Expected: "error[E0005]: Bad.\n" +
+				" --> main.ob:3:16\n" +
+				"  |\n" +
+				"3 | \tprint \"héllo\" + nil;\n" +
+				"  | \t              ^\n",
+		},
+		{
+			Name:       "Spans stop at the end of the line",
+			Diagnostic: &Diagnostic{Code: CodeToken, Message: "Unterminated string.", Span: Span{"main.ob", 4, 7, 9}},
+			Expected: "error[E0001]: Unterminated string.\n" +
+				" --> main.ob:4:7\n" +
+				"  |\n" +
+				"4 | print \"one\n" +
+				"  |       ^^^^\n",
+		},
+		{
+			Name:       "Warnings and notes",
+			Diagnostic: &Diagnostic{Severity: SeverityWarning, Code: CodeResolveWarning, Message: "Unused.", Span: Span{"", 1, 7, 1}, Notes: []string{"first", "second"}},
+			Expected: "warning[W0003]: Unused.\n" +
+				" --> 1:7\n" +
+				"  |\n" +
+				"1 | print x;\n" +
+				"  |       ^\n" +
+				"  = note: first\n" +
+				"  = note: second\n",
+		},
+		{
+			Name: "Traceback with repeated frames",
+			Diagnostic: &Diagnostic{
+				Code:    CodeRuntime,
+				Message: "Stack overflow.",
+				Span:    Span{"main.ob", 2, 9, 1},
+				Trace: []Frame{
+					{"<script>", Span{"main.ob", 1, 5, 1}},
+					{"f", Span{"main.ob", 2, 9, 1}},
+					{"f", Span{"main.ob", 2, 9, 1}},
+					{"f", Span{"main.ob", 2, 9, 1}},
+					{"f", Span{"main.ob", 2, 9, 1}},
+					{"f", Span{"main.ob", 2, 9, 1}},
+					{"g", Span{"lib.ob", 4, 1, 1}},
+				},
+			},
+			Expected: "Traceback (most recent call last):\n" +
+				"  main.ob:1:5 in <script>\n" +
+				"    var a = 1;\n" +
+				"  main.ob:2:9 in f\n" +
+				"    print a + a + nil + a;\n" +
+				"  main.ob:2:9 in f\n" +
+				"    print a + a + nil + a;\n" +
+				"  main.ob:2:9 in f\n" +
+				"    print a + a + nil + a;\n" +
+				"  [Previous frame repeated 2 more times]\n" +
+				"  lib.ob:4:1 in g\n" +
+				"error[E0005]: Stack overflow.\n" +
+				" --> main.ob:2:9\n" +
+				"  |\n" +
+				"2 | print a + a + nil + a;\n" +
+				"  |         ^\n",
+		},
+		{
+			Name:       "Unknown source",
+			Diagnostic: &Diagnostic{Code: CodeRuntime, Message: "Bad.", Span: Span{"other.obc", 12, 3, 1}},
+			Expected:   "error[E0005]: Bad.\n  --> other.obc:12:3\n",
+		},
+		{
+			Name:       "Unknown location",
+			Diagnostic: &Diagnostic{Code: CodeCompile, Message: "Bad."},
+			Expected:   "error[E0004]: Bad.\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+		out := &bytes.Buffer{}
+		sources.Render(out, test.Diagnostic)
+
+		if out.String() != test.Expected {
+			t.Errorf("%s: expected\n%s\nbut got\n%s", test.Name, test.Expected, out.String())
+		}
+	}
+}
+
+func TestReportPlainError(t *testing.T) {
+	out := &bytes.Buffer{}
+	Sources{}.Report(out, errors.New("main.ob: no such file"))
+
+	if out.String() != "main.ob: no such file\n" {
+		t.Errorf("expected the error as is but got %q", out.String())
+	}
+}
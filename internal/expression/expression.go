@@ -11,6 +11,16 @@ type Visitor interface {
 	VisitAssignExpression(*AssignExpression) (interface{}, error)
 	VisitLogicalExpression(*LogicalExpression) (interface{}, error)
 	VisitCallExpression(*CallExpression) (interface{}, error)
+	VisitGetExpression(*GetExpression) (interface{}, error)
+	VisitSetExpression(*SetExpression) (interface{}, error)
+	VisitThisExpression(*ThisExpression) (interface{}, error)
+	VisitSuperExpression(*SuperExpression) (interface{}, error)
+	VisitFunctionExpression(*FunctionExpression) (interface{}, error)
+	VisitListExpression(*ListExpression) (interface{}, error)
+	VisitIndexExpression(*IndexExpression) (interface{}, error)
+	VisitIndexSetExpression(*IndexSetExpression) (interface{}, error)
+	VisitMapExpression(*MapExpression) (interface{}, error)
+	VisitInterpolationExpression(*InterpolationExpression) (interface{}, error)
 }
 
 type Expression interface {
@@ -134,3 +144,171 @@ func (c *CallExpression) Accept(v Visitor) (interface{}, error) {
 func NewCallExpression(callee Expression, paren tokens.Token, arguments []Expression) *CallExpression {
 	return &CallExpression{callee, paren, arguments}
 }
+
+// GetExpression represents a property access on an instance
+type GetExpression struct {
+	Object Expression
+	Name   tokens.Token
+}
+
+// Accept handles get expression instances
+func (g *GetExpression) Accept(v Visitor) (interface{}, error) {
+	return v.VisitGetExpression(g)
+}
+
+// NewGetExpression makes a new property access from provided input
+func NewGetExpression(object Expression, name tokens.Token) *GetExpression {
+	return &GetExpression{object, name}
+}
+
+// SetExpression represents an assignment to a property on an instance
+type SetExpression struct {
+	Object Expression
+	Name   tokens.Token
+	Value  Expression
+}
+
+// Accept handles set expression instances
+func (s *SetExpression) Accept(v Visitor) (interface{}, error) {
+	return v.VisitSetExpression(s)
+}
+
+// NewSetExpression makes a new property assignment from provided input
+func NewSetExpression(object Expression, name tokens.Token, value Expression) *SetExpression {
+	return &SetExpression{object, name, value}
+}
+
+// ThisExpression represents the instance a method was called on
+type ThisExpression struct {
+	Keyword tokens.Token
+}
+
+// Accept handles this expression instances
+func (t *ThisExpression) Accept(v Visitor) (interface{}, error) {
+	return v.VisitThisExpression(t)
+}
+
+// NewThisExpression makes a new this expression from provided input
+func NewThisExpression(keyword tokens.Token) *ThisExpression {
+	return &ThisExpression{keyword}
+}
+
+// SuperExpression represents a method lookup that starts at the superclass
+type SuperExpression struct {
+	Keyword tokens.Token
+	Method  tokens.Token
+}
+
+// Accept handles super expression instances
+func (s *SuperExpression) Accept(v Visitor) (interface{}, error) {
+	return v.VisitSuperExpression(s)
+}
+
+// NewSuperExpression makes a new super expression from provided input
+func NewSuperExpression(keyword, method tokens.Token) *SuperExpression {
+	return &SuperExpression{keyword, method}
+}
+
+// FunctionExpression represents an anonymous function used as a value
+type FunctionExpression struct {
+	Keyword   tokens.Token
+	Arguments []tokens.Token
+
+	// Body holds a []statement.Statement, it is left untyped since statements import expressions
+	Body interface{}
+}
+
+// Accept handles function expression instances
+func (f *FunctionExpression) Accept(v Visitor) (interface{}, error) {
+	return v.VisitFunctionExpression(f)
+}
+
+// NewFunctionExpression makes a new anonymous function from provided input
+func NewFunctionExpression(keyword tokens.Token, arguments []tokens.Token, body interface{}) *FunctionExpression {
+	return &FunctionExpression{keyword, arguments, body}
+}
+
+// ListExpression represents a list literal
+type ListExpression struct {
+	Bracket  tokens.Token
+	Elements []Expression
+}
+
+// Accept handles list expression instances
+func (l *ListExpression) Accept(v Visitor) (interface{}, error) {
+	return v.VisitListExpression(l)
+}
+
+// NewListExpression makes a new list literal from provided input
+func NewListExpression(bracket tokens.Token, elements []Expression) *ListExpression {
+	return &ListExpression{bracket, elements}
+}
+
+// IndexExpression represents a subscript read like xs[i]
+type IndexExpression struct {
+	Object  Expression
+	Bracket tokens.Token
+	Index   Expression
+}
+
+// Accept handles index expression instances
+func (i *IndexExpression) Accept(v Visitor) (interface{}, error) {
+	return v.VisitIndexExpression(i)
+}
+
+// NewIndexExpression makes a new subscript read from provided input
+func NewIndexExpression(object Expression, bracket tokens.Token, index Expression) *IndexExpression {
+	return &IndexExpression{object, bracket, index}
+}
+
+// IndexSetExpression represents a subscript write like xs[i] = v
+type IndexSetExpression struct {
+	Object  Expression
+	Bracket tokens.Token
+	Index   Expression
+	Value   Expression
+}
+
+// Accept handles index set expression instances
+func (i *IndexSetExpression) Accept(v Visitor) (interface{}, error) {
+	return v.VisitIndexSetExpression(i)
+}
+
+// NewIndexSetExpression makes a new subscript write from provided input
+func NewIndexSetExpression(object Expression, bracket tokens.Token, index, value Expression) *IndexSetExpression {
+	return &IndexSetExpression{object, bracket, index, value}
+}
+
+// MapExpression represents a map literal, Keys and Values are parallel
+type MapExpression struct {
+	Brace  tokens.Token
+	Keys   []Expression
+	Values []Expression
+}
+
+// Accept handles map expression instances
+func (m *MapExpression) Accept(v Visitor) (interface{}, error) {
+	return v.VisitMapExpression(m)
+}
+
+// NewMapExpression makes a new map literal from provided input
+func NewMapExpression(brace tokens.Token, keys, values []Expression) *MapExpression {
+	return &MapExpression{brace, keys, values}
+}
+
+// InterpolationExpression represents a string with ${} expressions in it. Parts holds the string
+// segments and expressions in order, the first part is always a string segment.
+type InterpolationExpression struct {
+	Start tokens.Token
+	Parts []Expression
+}
+
+// Accept handles interpolation expression instances
+func (i *InterpolationExpression) Accept(v Visitor) (interface{}, error) {
+	return v.VisitInterpolationExpression(i)
+}
+
+// NewInterpolationExpression makes a new interpolated string from provided input
+func NewInterpolationExpression(start tokens.Token, parts []Expression) *InterpolationExpression {
+	return &InterpolationExpression{start, parts}
+}
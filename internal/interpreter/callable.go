@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/jparr721/obsidian/internal/statement"
+	"github.com/jparr721/obsidian/internal/tokens"
 )
 
 // Callable represents a callable type which takes arguments and an interpreter instance
@@ -12,34 +13,76 @@ type Callable interface {
 	Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error)
 }
 
+// Variadic is the arity of natives that take any number of arguments
+const Variadic = -1
+
+// AcceptsArguments reports whether a callable can be called with argc arguments
+func AcceptsArguments(callable Callable, argc int) bool {
+	return callable.Arity() == Variadic || callable.Arity() == argc
+}
+
 // Function represents a function callable
 type Function struct {
 	Declaration *statement.FunctionStatement
+
+	// closure is the environment the function was declared in, each call's scope encloses it
+	closure       *environment
+	isInitializer bool
+}
+
+func NewFunction(declaration *statement.FunctionStatement, closure *environment) *Function {
+	return &Function{declaration, closure, false}
+}
+
+// NewMethod creates a function declared inside of a class body
+func NewMethod(declaration *statement.FunctionStatement, closure *environment, isInitializer bool) *Function {
+	return &Function{declaration, closure, isInitializer}
 }
 
-func NewFunction(declaration *statement.FunctionStatement) *Function {
-	return &Function{declaration}
+// bind returns a copy of the method whose closure defines "this" as instance
+func (f *Function) bind(instance *Instance) *Function {
+	environment := NewEnvironment(f.closure)
+	environment.define("this", instance)
+	return &Function{f.Declaration, environment, f.isInitializer}
+}
+
+// this reads the bound instance back out of an initializer's closure
+func (f *Function) this() interface{} {
+	return f.closure.values["this"]
 }
 
 func (f *Function) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
-	environment := NewEnvironment(interpreter.globals)
+	environment := NewEnvironment(f.closure)
 
 	for i, arg := range arguments {
 		lexeme := f.Declaration.Arguments[i].Lexeme
 		environment.define(lexeme, arg)
 	}
 
+	// Globals resolve in the module the function was declared in
+	previous := interpreter.globals
+	interpreter.globals = f.closure.root()
 	err := interpreter.executeBlock(f.Declaration.Body, environment)
+	interpreter.globals = previous
 
 	if err != nil {
 		switch err.(type) {
 		case *RuntimeError:
 			return nil, err
 		case *ReturnInterrupt:
+			// Initializers always hand back the instance, even on an early return
+			if f.isInitializer {
+				return f.this(), nil
+			}
+
 			return err.(*ReturnInterrupt).value, nil
 		}
 	}
 
+	if f.isInitializer {
+		return f.this(), nil
+	}
+
 	return nil, nil
 }
 
@@ -48,5 +91,10 @@ func (f *Function) Arity() int {
 }
 
 func (f *Function) String() string {
+	// Anonymous functions are named by their "fun" keyword
+	if f.Declaration.Name.Variant != tokens.TokenIdentifier {
+		return "<fn anonymous>"
+	}
+
 	return fmt.Sprintf("<fn %s>", f.Declaration.Name.Lexeme)
 }
@@ -0,0 +1,88 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+// Class represents a class value, calling it constructs a new instance
+type Class struct {
+	Name       string
+	Superclass *Class
+	Methods    map[string]*Function
+}
+
+func NewClass(name string, superclass *Class, methods map[string]*Function) *Class {
+	return &Class{name, superclass, methods}
+}
+
+// findMethod walks up the superclass chain until it finds name
+func (c *Class) findMethod(name string) (*Function, bool) {
+	if method, ok := c.Methods[name]; ok {
+		return method, true
+	}
+
+	if c.Superclass != nil {
+		return c.Superclass.findMethod(name)
+	}
+
+	return nil, false
+}
+
+// Call creates an instance and runs its initializer when one is declared
+func (c *Class) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	instance := NewInstance(c)
+
+	if initializer, ok := c.findMethod("init"); ok {
+		if _, err := initializer.bind(instance).Call(interpreter, arguments); err != nil {
+			return nil, err
+		}
+	}
+
+	return instance, nil
+}
+
+// Arity matches the initializer, classes without one take no arguments
+func (c *Class) Arity() int {
+	if initializer, ok := c.findMethod("init"); ok {
+		return initializer.Arity()
+	}
+
+	return 0
+}
+
+func (c *Class) String() string {
+	return c.Name
+}
+
+// Instance represents an object created from a class
+type Instance struct {
+	class  *Class
+	fields map[string]interface{}
+}
+
+func NewInstance(class *Class) *Instance {
+	return &Instance{class, make(map[string]interface{})}
+}
+
+// get looks up fields first so they shadow methods of the same name
+func (in *Instance) get(name tokens.Token) (interface{}, error) {
+	if value, ok := in.fields[name.Lexeme]; ok {
+		return value, nil
+	}
+
+	if method, ok := in.class.findMethod(name.Lexeme); ok {
+		return method.bind(in), nil
+	}
+
+	return nil, NewRuntimeError(name, fmt.Sprintf("Undefined property '%s'.", name.Lexeme))
+}
+
+func (in *Instance) set(name tokens.Token, value interface{}) {
+	in.fields[name.Lexeme] = value
+}
+
+func (in *Instance) String() string {
+	return fmt.Sprintf("<%s instance>", in.class.Name)
+}
@@ -9,10 +9,13 @@ import (
 type environment struct {
 	enclosing *environment
 	values    map[string]interface{}
+
+	// natives marks the scope a module's globals enclose, it is not part of the module
+	natives bool
 }
 
 func NewEnvironment(enclosing *environment) *environment {
-	return &environment{enclosing, make(map[string]interface{})}
+	return &environment{enclosing, make(map[string]interface{}), false}
 }
 
 // TODO(@jparr721) - Make values immutable
@@ -34,7 +37,37 @@ func (e *environment) get(name tokens.Token) (interface{}, error) {
 		return e.enclosing.get(name)
 	}
 
-	return nil, newRuntimeError(name, fmt.Sprintf("Undefined variable '%s'", name.Lexeme))
+	return nil, NewRuntimeError(name, fmt.Sprintf("Undefined variable '%s'", name.Lexeme))
+}
+
+// ancestor walks distance scopes up the enclosing chain
+func (e *environment) ancestor(distance int) *environment {
+	environment := e
+	for i := 0; i < distance; i++ {
+		environment = environment.enclosing
+	}
+
+	return environment
+}
+
+// root returns the global scope at the end of the enclosing chain, below a module's natives
+func (e *environment) root() *environment {
+	environment := e
+	for environment.enclosing != nil && !environment.enclosing.natives {
+		environment = environment.enclosing
+	}
+
+	return environment
+}
+
+// getAt reads a name the resolver already located distance scopes up
+func (e *environment) getAt(distance int, name string) interface{} {
+	return e.ancestor(distance).values[name]
+}
+
+// assignAt writes a name the resolver already located distance scopes up
+func (e *environment) assignAt(distance int, name tokens.Token, value interface{}) {
+	e.ancestor(distance).values[name.Lexeme] = value
 }
 
 func (e *environment) assign(name tokens.Token, value interface{}) error {
@@ -47,5 +80,5 @@ func (e *environment) assign(name tokens.Token, value interface{}) error {
 		return e.enclosing.assign(name, value)
 	}
 
-	return newRuntimeError(name, fmt.Sprintf("Undefined variable '%s'", name.Lexeme))
+	return NewRuntimeError(name, fmt.Sprintf("Undefined variable '%s'", name.Lexeme))
 }
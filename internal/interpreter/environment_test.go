@@ -85,7 +85,7 @@ func TestGet(t *testing.T) {
 			VarName:   "",
 			VarValue:  nil,
 			Enclosing: nil,
-			Expected:  newRuntimeError(tokens.NewToken(tokens.TokenIdentifier, "bar", nil, 1), "Undefined variable 'bar'"),
+			Expected:  NewRuntimeError(tokens.NewToken(tokens.TokenIdentifier, "bar", nil, 1), "Undefined variable 'bar'"),
 		},
 		{
 			Name:      "Gets from enclosing when enclosing is not nil",
@@ -3,6 +3,7 @@ package interpreter
 import (
 	"fmt"
 
+	"github.com/jparr721/obsidian/internal/diagnostics"
 	"github.com/jparr721/obsidian/internal/tokens"
 )
 
@@ -10,16 +11,51 @@ import (
 type RuntimeError struct {
 	token   tokens.Token
 	message string
+
+	// trace is the call stack when the error was raised, nil until it has been recorded
+	trace []Frame
+
+	// thrown is the value a throw statement raised, nil for errors raised by the language itself
+	thrown interface{}
+}
+
+func NewRuntimeError(token tokens.Token, message string) *RuntimeError {
+	return &RuntimeError{token, message, nil, nil}
+}
+
+// WithTrace records a copy of the call stack the error was raised in
+func (r *RuntimeError) WithTrace(frames []Frame) *RuntimeError {
+	r.trace = make([]Frame, len(frames))
+	copy(r.trace, frames)
+	return r
 }
 
-func newRuntimeError(token tokens.Token, message string) *RuntimeError {
-	return &RuntimeError{token, message}
+// Trace returns the calls that were in progress when the error was raised, innermost last
+func (r *RuntimeError) Trace() []Frame {
+	return r.trace
+}
+
+// Traceback lists where each call was executing when the error was raised, ending at the error
+func (r *RuntimeError) Traceback() []diagnostics.Frame {
+	return Traceback(r.trace, r.token)
 }
 
 func (r *RuntimeError) Error() string {
 	return fmt.Sprintf("RuntimeError: [line %d] %s", r.token.Line, r.message)
 }
 
+// Diagnostic points at the operator, name or call the error was raised at, errors raised
+// inside a call carry a traceback
+func (r *RuntimeError) Diagnostic() *diagnostics.Diagnostic {
+	d := &diagnostics.Diagnostic{Code: diagnostics.CodeRuntime, Message: r.message, Span: r.token.Span()}
+
+	if len(r.trace) > 0 {
+		d.Trace = r.Traceback()
+	}
+
+	return d
+}
+
 // ReturnInterrupt represents the return statement and its value as an error to break nested calls.
 type ReturnInterrupt struct {
 	value interface{}
@@ -32,3 +68,27 @@ func newReturnInterrupt(value interface{}) *ReturnInterrupt {
 func (r *ReturnInterrupt) Error() string {
 	return stringify(r.value)
 }
+
+// LoopInterrupt represents break and continue as an error to leave the statements of a loop
+// body. label is empty when the innermost loop is meant.
+type LoopInterrupt struct {
+	keyword string
+	label   string
+}
+
+func newLoopInterrupt(keyword string, label tokens.Token) *LoopInterrupt {
+	return &LoopInterrupt{keyword, label.Lexeme}
+}
+
+// targets reports whether the interrupt is meant for the loop labeled label
+func (l *LoopInterrupt) targets(label tokens.Token) bool {
+	return l.label == "" || l.label == label.Lexeme
+}
+
+func (l *LoopInterrupt) Error() string {
+	if l.label == "" {
+		return l.keyword
+	}
+
+	return l.keyword + " " + l.label
+}
@@ -0,0 +1,93 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+// ErrorValue is what a catch clause receives. Errors raised by the language and values thrown
+// by a program are both caught as one, a thrown value that isn't an error is kept in Value.
+type ErrorValue struct {
+	Message string
+	Line    int
+
+	// Stack lists where each call was executing when the error was raised, innermost last
+	Stack *List
+	Value interface{}
+
+	// cause is the error that was caught, rethrowing the value raises it again unchanged
+	cause *RuntimeError
+}
+
+// NewErrorValue creates an error that has not been thrown yet
+func NewErrorValue(message string) *ErrorValue {
+	return &ErrorValue{Message: message, Stack: NewList(make([]interface{}, 0))}
+}
+
+func (e *ErrorValue) String() string {
+	return "Error: " + e.Message
+}
+
+// Get reads one of the error's properties
+func (e *ErrorValue) Get(name string) (interface{}, bool) {
+	switch name {
+	case "message":
+		return e.Message, true
+	case "line":
+		return int64(e.Line), true
+	case "stack":
+		return e.Stack, true
+	case "value":
+		return e.Value, true
+	}
+
+	return nil, false
+}
+
+// Throw raises value at token. Rethrowing a caught error raises the original again so it keeps
+// the place and the stack it came from.
+func Throw(value interface{}, token tokens.Token) *RuntimeError {
+	if e, ok := value.(*ErrorValue); ok {
+		if e.cause != nil {
+			return e.cause
+		}
+
+		err := NewRuntimeError(token, e.Message)
+		err.thrown = e
+		return err
+	}
+
+	err := NewRuntimeError(token, stringify(value))
+	err.thrown = value
+	return err
+}
+
+// Catch turns err into the value a catch clause binds. frames is the stack at the catch, it
+// stands in for the stack err was raised in when err never left the function that raised it.
+func Catch(err *RuntimeError, frames []Frame) *ErrorValue {
+	if err.trace == nil {
+		err.WithTrace(frames)
+	}
+
+	e, ok := err.thrown.(*ErrorValue)
+	if !ok {
+		e = NewErrorValue(err.message)
+		e.Value = err.thrown
+	}
+
+	stack := make([]interface{}, 0)
+	for _, frame := range err.Traceback() {
+		stack = append(stack, fmt.Sprintf("%s at %s", frame.Name, frame.Span))
+	}
+
+	e.Line = err.token.Line
+	e.Stack = NewList(stack)
+	e.cause = err
+	return e
+}
+
+// newError is the Error native, it creates an error value to throw
+func newError(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	return NewErrorValue(stringify(arguments[0])), nil
+}
@@ -0,0 +1,113 @@
+package interpreter
+
+import (
+	"github.com/jparr721/obsidian/internal/diagnostics"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+// maxFrames bounds the call depth like the virtual machine does, counting the script's own frame
+const maxFrames = 1024
+
+// Frame is a call in progress: the name of what was called, the token at the call site and the
+// callee itself. Calls made from Go have no call site.
+type Frame struct {
+	Name   string
+	Call   tokens.Token
+	Callee interface{}
+}
+
+// Frames returns the calls in progress, innermost last. Natives see their own call on top.
+func (i *Interpreter) Frames() []Frame {
+	frames := make([]Frame, len(i.frames))
+	copy(frames, i.frames)
+	return frames
+}
+
+// Call invokes callee from Go, the frame pushed for it has no call site
+func (i *Interpreter) Call(callee Callable, arguments []interface{}) (interface{}, error) {
+	return i.call(callee, arguments, tokens.Token{})
+}
+
+// CallNative runs native for a backend that keeps its own call stack, frames are the calls in
+// progress and at is the call site. The native sees the frames as if this interpreter made them.
+func (i *Interpreter) CallNative(native Callable, arguments []interface{}, frames []Frame, at tokens.Token) (interface{}, error) {
+	previous := i.frames
+	i.frames = frames
+	defer func() { i.frames = previous }()
+
+	return i.call(native, arguments, at)
+}
+
+// call invokes callee with a frame pushed for it, runtime errors escaping it record the stack
+// as it was when they were raised
+func (i *Interpreter) call(callee Callable, arguments []interface{}, at tokens.Token) (interface{}, error) {
+	if len(i.frames)+1 == maxFrames {
+		return nil, NewRuntimeError(at, "Stack overflow.").WithTrace(i.frames)
+	}
+
+	i.frames = append(i.frames, Frame{frameName(callee), at, callee})
+	defer func() { i.frames = i.frames[:len(i.frames)-1] }()
+
+	value, err := callee.Call(i, arguments)
+
+	if err == nil {
+		return value, nil
+	}
+
+	if e, ok := err.(*RuntimeError); ok {
+		if e.trace == nil {
+			e.WithTrace(i.frames)
+		}
+
+		return nil, e
+	}
+
+	// Natives report plain errors, pin them to the call site. Their own frame is left out since
+	// the error already points at the call.
+	return nil, NewRuntimeError(at, err.Error()).WithTrace(i.frames[:len(i.frames)-1])
+}
+
+// frameName is how a callee is listed in a traceback
+func frameName(callee Callable) string {
+	switch c := callee.(type) {
+	case *Function:
+		if c.Declaration.Name.Variant != tokens.TokenIdentifier {
+			return "anonymous"
+		}
+
+		return c.Declaration.Name.Lexeme
+	case *Class:
+		// Calling a class runs its initializer
+		if _, ok := c.findMethod("init"); ok {
+			return "init"
+		}
+
+		return c.Name
+	case *NativeFunction:
+		return c.name
+	}
+
+	return "<unknown>"
+}
+
+// Traceback lists where each frame was executing, oldest first. Every frame is at the call of
+// the one above it and the innermost is at the token at. Locations that aren't known, like the
+// Go side of a call made from Go, are left out.
+func Traceback(frames []Frame, at tokens.Token) []diagnostics.Frame {
+	trace := make([]diagnostics.Frame, 0, len(frames)+1)
+	name := "<script>"
+
+	for _, frame := range frames {
+		if frame.Call.Line > 0 {
+			trace = append(trace, diagnostics.Frame{Name: name, Span: frame.Call.Span()})
+		}
+
+		name = frame.Name
+	}
+
+	if at.Line > 0 {
+		trace = append(trace, diagnostics.Frame{Name: name, Span: at.Span()})
+	}
+
+	return trace
+}
@@ -2,48 +2,207 @@ package interpreter
 
 import (
 	"fmt"
+	"io"
+	"math/rand"
+	"os"
 	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/jparr721/obsidian/internal/expression"
+	"github.com/jparr721/obsidian/internal/module"
 	"github.com/jparr721/obsidian/internal/statement"
 	"github.com/jparr721/obsidian/internal/tokens"
 )
 
+// Stringify renders a runtime value the same way print does
+func Stringify(evaluated interface{}) string {
+	return stringify(evaluated)
+}
+
 func stringify(evaluated interface{}) string {
 	if evaluated == nil {
 		return "nil"
 	}
 
-	if reflect.TypeOf(evaluated).String() == "float64" {
-		return strconv.FormatFloat(evaluated.(float64), 'f', -1, 64)
+	switch number := evaluated.(type) {
+	case float64:
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(number, 10)
 	}
 
 	return fmt.Sprintf("%v", evaluated)
 }
 
+// IsTruthy reports whether a value passes a condition, only nil and false are falsy
+func IsTruthy(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+
+	if b, ok := value.(bool); ok {
+		return b
+	}
+
+	return true
+}
+
+// IsEqual compares two values the way == does. Integers and floats with the same value are
+// equal, lists and maps compare their contents and everything else compares by identity.
+func IsEqual(a, b interface{}) bool {
+	return isEqual(a, b, make(map[comparison]bool))
+}
+
+// comparison is a pair of containers being compared
+type comparison struct {
+	a, b interface{}
+}
+
+// isEqual compares a and b, comparing holds the pairs of containers already being compared
+// further out. Meeting one of them again means a cycle, which is taken as equal so far and left
+// to the rest of the comparison to decide.
+func isEqual(a, b interface{}, comparing map[comparison]bool) bool {
+	if IsNumber(a) && IsNumber(b) {
+		return compareNumbers(a, b) == 0
+	}
+
+	switch x := a.(type) {
+	case *List:
+		y, ok := b.(*List)
+		if !ok || len(x.Elements) != len(y.Elements) {
+			return false
+		}
+
+		pair := comparison{x, y}
+		if x == y || comparing[pair] {
+			return true
+		}
+
+		comparing[pair] = true
+		defer delete(comparing, pair)
+
+		for n := range x.Elements {
+			if !isEqual(x.Elements[n], y.Elements[n], comparing) {
+				return false
+			}
+		}
+
+		return true
+	case *Map:
+		y, ok := b.(*Map)
+		if !ok || len(x.keys) != len(y.keys) {
+			return false
+		}
+
+		pair := comparison{x, y}
+		if x == y || comparing[pair] {
+			return true
+		}
+
+		comparing[pair] = true
+		defer delete(comparing, pair)
+
+		for _, key := range x.keys {
+			value, ok := y.Get(key)
+			if !ok || !isEqual(x.values[key], value, comparing) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	// Everything else is either a plain value or a reference that is only equal to itself, like
+	// instances, classes and functions. Go's == compares both of those the right way.
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return reflect.TypeOf(a).Comparable() && a == b
+}
+
 type Interpreter struct {
 	// globals are the global native objects, constants, and functions
-	globals      *environment
-	environment  *environment
-	loopDidBreak bool
+	globals     *environment
+	environment *environment
+
+	// locals maps each resolved variable reference to its scope depth, the rest are globals
+	locals map[expression.Expression]int
+
+	// out is where print statements write to
+	out io.Writer
+
+	// loader finds and caches modules, file is the file whose top level is running
+	loader *module.Loader
+	file   string
+
+	// frames are the calls in progress, innermost last
+	frames []Frame
+
+	// generator is where random and randint draw from
+	generator *rand.Rand
 }
 
 func NewInterpreter() *Interpreter {
-	environment := NewEnvironment(nil)
 	globals := NewEnvironment(nil)
-	globals.define("clock", new(clockFunction))
+	defineNatives(globals)
+
+	// Top level declarations live alongside the natives so functions can see them
+	return &Interpreter{globals, globals, make(map[expression.Expression]int), os.Stdout, module.NewLoader(), "", make([]Frame, 0), newGenerator()}
+}
+
+// SetLoader replaces the module loader, for example to add search paths
+func (i *Interpreter) SetLoader(loader *module.Loader) {
+	i.loader = loader
+}
+
+// SetFile records the path of the file being run, imports are relative to it
+func (i *Interpreter) SetFile(file string) {
+	i.file = file
+}
+
+// Resolve records the scope depths computed by the resolver pass
+func (i *Interpreter) Resolve(locals map[expression.Expression]int) {
+	for e, depth := range locals {
+		i.locals[e] = depth
+	}
+}
+
+func (i *Interpreter) lookUpVariable(name tokens.Token, e expression.Expression) (interface{}, error) {
+	if distance, ok := i.locals[e]; ok {
+		return i.environment.getAt(distance, name.Lexeme), nil
+	}
+
+	return i.globals.get(name)
+}
+
+// SetOutput redirects print statements to w
+func (i *Interpreter) SetOutput(w io.Writer) {
+	i.out = w
+}
+
+// Define binds name to value in the global scope
+func (i *Interpreter) Define(name string, value interface{}) {
+	i.globals.define(name, value)
+}
 
-	return &Interpreter{globals, environment, false}
+// Global looks up a name in the global scope
+func (i *Interpreter) Global(name string) (interface{}, bool) {
+	value, ok := i.globals.values[name]
+	return value, ok
+}
+
+// Evaluate evaluates a single expression in the current environment
+func (i *Interpreter) Evaluate(e expression.Expression) (interface{}, error) {
+	return i.evaluate(e)
 }
 
 func (i *Interpreter) Interpret(statements []statement.Statement) error {
 	for _, statement := range statements {
-    fmt.Println(i.environment)
 		_, err := i.execute(statement)
 
 		if err != nil {
-			fmt.Println(err)
 			return err
 		}
 	}
@@ -57,22 +216,17 @@ func (i *Interpreter) executeBlock(statements []statement.Statement, environment
 	// Lift into new environment context
 	i.environment = environment
 
+	// Hand the environment back, even when the block exits early
+	defer func() { i.environment = previous }()
+
 	// Run everything in this scope
 	for _, statement := range statements {
-    fmt.Println(reflect.TypeOf(statement))
-		if reflect.TypeOf(statement).String() == "*statement.BreakStatement" {
-			i.loopDidBreak = true
-			break
-		}
-
 		_, err := i.execute(statement)
 		if err != nil {
 			return err
 		}
 	}
 
-	// Hand the environment back
-	i.environment = previous
 	return nil
 }
 
@@ -85,37 +239,166 @@ func (i *Interpreter) evaluate(e expression.Expression) (interface{}, error) {
 }
 
 func (i *Interpreter) VisitFunctionStatement(s *statement.FunctionStatement) (interface{}, error) {
-	function := NewFunction(s)
+	function := NewFunction(s, i.environment)
 	i.environment.define(s.Name.Lexeme, function)
 	return nil, nil
 }
 
-func (i *Interpreter) VisitBreakStatement(s *statement.BreakStatement) (interface{}, error) {
+func (i *Interpreter) VisitClassStatement(s *statement.ClassStatement) (interface{}, error) {
+	var superclass *Class
+
+	if s.Superclass != nil {
+		value, err := i.evaluate(s.Superclass)
+
+		if err != nil {
+			return nil, err
+		}
+
+		class, ok := value.(*Class)
+
+		if !ok {
+			return nil, NewRuntimeError(s.Superclass.Name, "Superclass must be a class.")
+		}
+
+		superclass = class
+	}
+
+	i.environment.define(s.Name.Lexeme, nil)
+
+	// Methods of a subclass close over a scope holding "super"
+	enclosing := i.environment
+	if superclass != nil {
+		i.environment = NewEnvironment(i.environment)
+		i.environment.define("super", superclass)
+	}
+
+	methods := make(map[string]*Function)
+	for _, method := range s.Methods {
+		methods[method.Name.Lexeme] = NewMethod(method, i.environment, method.Name.Lexeme == "init")
+	}
+
+	i.environment = enclosing
+
+	return nil, i.environment.assign(s.Name, NewClass(s.Name.Lexeme, superclass, methods))
+}
+
+func (i *Interpreter) VisitImportStatement(s *statement.ImportStatement) (interface{}, error) {
+	// The module's top level runs in a frame of its own, called from the import
+	i.frames = append(i.frames, Frame{"<script>", s.Keyword, nil})
+	imported, err := i.loader.Import(i.file, s.Path.Literal.(string), i.runModule)
+
+	if e, ok := err.(*RuntimeError); ok && e.trace == nil {
+		e.WithTrace(i.frames)
+	}
+
+	i.frames = i.frames[:len(i.frames)-1]
+
+	if err != nil {
+		// Errors pointing into the module are reported there rather than at the import
+		if module.Located(err) {
+			return nil, err
+		}
+
+		return nil, NewRuntimeError(s.Keyword, err.Error())
+	}
+
+	i.environment.define(s.Name.Lexeme, imported)
 	return nil, nil
 }
 
-func (i *Interpreter) VisitWhileStatement(s *statement.WhileStatement) (interface{}, error) {
-	for {
-		if i.loopDidBreak {
-			i.loopDidBreak = false
-			break
+// runModule runs a module file with its own globals and hands them back
+func (i *Interpreter) runModule(file string) (map[string]interface{}, error) {
+	statements, locals, err := i.loader.Parse(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	i.Resolve(locals)
+
+	// Natives live in a scope of their own so the module only exports what it declares
+	natives := NewEnvironment(nil)
+	natives.natives = true
+	defineNatives(natives)
+	globals := NewEnvironment(natives)
+
+	previousGlobals, previousEnvironment, previousFile := i.globals, i.environment, i.file
+	i.globals, i.environment, i.file = globals, globals, file
+	defer func() { i.globals, i.environment, i.file = previousGlobals, previousEnvironment, previousFile }()
+
+	return globals.values, i.Interpret(statements)
+}
+
+func (i *Interpreter) VisitBreakStatement(s *statement.BreakStatement) (interface{}, error) {
+	return nil, newLoopInterrupt("break", s.Label)
+}
+
+func (i *Interpreter) VisitContinueStatement(s *statement.ContinueStatement) (interface{}, error) {
+	return nil, newLoopInterrupt("continue", s.Label)
+}
+
+func (i *Interpreter) VisitThrowStatement(s *statement.ThrowStatement) (interface{}, error) {
+	value, err := i.evaluate(s.Value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, Throw(value, s.Keyword)
+}
+
+// VisitTryStatement hands runtime errors from the body to the catch clause, returns and breaks
+// pass through. The finally clause runs however the rest ends and an error from it wins.
+func (i *Interpreter) VisitTryStatement(s *statement.TryStatement) (interface{}, error) {
+	err := i.executeBlock(s.Body, NewEnvironment(i.environment))
+
+	if caught, ok := err.(*RuntimeError); ok && s.Catch != nil {
+		environment := NewEnvironment(i.environment)
+		environment.define(s.CatchName.Lexeme, Catch(caught, i.frames))
+		err = i.executeBlock(s.Catch, environment)
+	}
+
+	if s.Finally != nil {
+		if finallyErr := i.executeBlock(s.Finally, NewEnvironment(i.environment)); finallyErr != nil {
+			return nil, finallyErr
 		}
+	}
+
+	return nil, err
+}
 
+func (i *Interpreter) VisitWhileStatement(s *statement.WhileStatement) (interface{}, error) {
+	for {
 		cond, err := i.evaluate(s.Condition)
 
 		if err != nil {
 			return nil, err
 		}
 
-		if !i.isTruthy(cond) {
+		if !IsTruthy(cond) {
 			break
 		}
 
 		_, err = i.execute(s.Body)
 
+		// Breaks and continues for an outer loop keep going out through this one
+		if interrupt, ok := err.(*LoopInterrupt); ok && interrupt.targets(s.Label) {
+			if interrupt.keyword == "break" {
+				break
+			}
+
+			err = nil
+		}
+
 		if err != nil {
 			return nil, err
 		}
+
+		if s.Increment != nil {
+			if _, err := i.evaluate(s.Increment); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return nil, nil
@@ -128,10 +411,10 @@ func (i *Interpreter) VisitIfStatement(s *statement.IfStatement) (interface{}, e
 		return nil, err
 	}
 
-	if i.isTruthy(cond) {
-		i.execute(s.ThenBranch)
+	if IsTruthy(cond) {
+		return i.execute(s.ThenBranch)
 	} else if s.ElseBranch != nil {
-		i.execute(s.ElseBranch)
+		return i.execute(s.ElseBranch)
 	}
 
 	return nil, nil
@@ -176,7 +459,7 @@ func (i *Interpreter) VisitPrintStatement(s *statement.PrintStatement) (interfac
 		return nil, err
 	}
 
-	fmt.Println(stringify(value))
+	fmt.Fprintln(i.out, stringify(value))
 
 	return nil, nil
 }
@@ -213,17 +496,219 @@ func (i *Interpreter) VisitCallExpression(e *expression.CallExpression) (interfa
 		arguments = append(arguments, a)
 	}
 
-	if reflect.TypeOf(callee).String() != "*interpreter.Function" {
-		return nil, newRuntimeError(e.Paren, "Only function and class types are callable.")
+	function, ok := callee.(Callable)
+
+	if !ok {
+		return nil, NewRuntimeError(e.Paren, "Only function and class types are callable.")
+	}
+
+	if !AcceptsArguments(function, len(arguments)) {
+		return nil, NewRuntimeError(e.Paren, fmt.Sprintf("Expected %d arguments, but got %d.", function.Arity(), len(arguments)))
 	}
 
-	function := callee.(Callable)
+	return i.call(function, arguments, e.Paren)
+}
+
+// VisitInterpolationExpression stringifies each part and joins them
+func (i *Interpreter) VisitInterpolationExpression(e *expression.InterpolationExpression) (interface{}, error) {
+	var b strings.Builder
 
-	if function.Arity() != len(arguments) {
-		return nil, newRuntimeError(e.Paren, fmt.Sprintf("Expected %d arguments, but got %d.", function.Arity(), len(arguments)))
+	for _, part := range e.Parts {
+		value, err := i.evaluate(part)
+
+		if err != nil {
+			return nil, err
+		}
+
+		b.WriteString(stringify(value))
 	}
 
-	return function.Call(i, arguments)
+	return b.String(), nil
+}
+
+func (i *Interpreter) VisitListExpression(e *expression.ListExpression) (interface{}, error) {
+	elements := make([]interface{}, 0, len(e.Elements))
+
+	for _, element := range e.Elements {
+		value, err := i.evaluate(element)
+
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, value)
+	}
+
+	return NewList(elements), nil
+}
+
+func (i *Interpreter) VisitMapExpression(e *expression.MapExpression) (interface{}, error) {
+	m := NewMap()
+
+	for n := range e.Keys {
+		key, err := i.evaluate(e.Keys[n])
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkKey(key); err != nil {
+			return nil, NewRuntimeError(e.Brace, err.Error())
+		}
+
+		value, err := i.evaluate(e.Values[n])
+
+		if err != nil {
+			return nil, err
+		}
+
+		m.Set(key, value)
+	}
+
+	return m, nil
+}
+
+func (i *Interpreter) VisitIndexExpression(e *expression.IndexExpression) (interface{}, error) {
+	object, err := i.evaluate(e.Object)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := i.evaluate(e.Index)
+
+	if err != nil {
+		return nil, err
+	}
+
+	indexable, ok := object.(Indexable)
+
+	if !ok {
+		return nil, NewRuntimeError(e.Bracket, "Only lists and maps can be indexed.")
+	}
+
+	value, err := indexable.Index(key)
+
+	if err != nil {
+		return nil, NewRuntimeError(e.Bracket, err.Error())
+	}
+
+	return value, nil
+}
+
+func (i *Interpreter) VisitIndexSetExpression(e *expression.IndexSetExpression) (interface{}, error) {
+	object, err := i.evaluate(e.Object)
+
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := i.evaluate(e.Index)
+
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := i.evaluate(e.Value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	indexable, ok := object.(Indexable)
+
+	if !ok {
+		return nil, NewRuntimeError(e.Bracket, "Only lists and maps can be indexed.")
+	}
+
+	if err := indexable.SetIndex(key, value); err != nil {
+		return nil, NewRuntimeError(e.Bracket, err.Error())
+	}
+
+	return value, nil
+}
+
+func (i *Interpreter) VisitGetExpression(e *expression.GetExpression) (interface{}, error) {
+	object, err := i.evaluate(e.Object)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if imported, ok := object.(*module.Module); ok {
+		value, ok := imported.Get(e.Name.Lexeme)
+
+		if !ok {
+			return nil, NewRuntimeError(e.Name, fmt.Sprintf("Module '%s' has no export '%s'.", imported.Name, e.Name.Lexeme))
+		}
+
+		return value, nil
+	}
+
+	if caught, ok := object.(*ErrorValue); ok {
+		value, ok := caught.Get(e.Name.Lexeme)
+
+		if !ok {
+			return nil, NewRuntimeError(e.Name, fmt.Sprintf("Undefined property '%s'.", e.Name.Lexeme))
+		}
+
+		return value, nil
+	}
+
+	instance, ok := object.(*Instance)
+
+	if !ok {
+		return nil, NewRuntimeError(e.Name, "Only instances have properties.")
+	}
+
+	return instance.get(e.Name)
+}
+
+func (i *Interpreter) VisitSetExpression(e *expression.SetExpression) (interface{}, error) {
+	object, err := i.evaluate(e.Object)
+
+	if err != nil {
+		return nil, err
+	}
+
+	instance, ok := object.(*Instance)
+
+	if !ok {
+		return nil, NewRuntimeError(e.Name, "Only instances have fields.")
+	}
+
+	value, err := i.evaluate(e.Value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	instance.set(e.Name, value)
+	return value, nil
+}
+
+func (i *Interpreter) VisitThisExpression(e *expression.ThisExpression) (interface{}, error) {
+	return i.lookUpVariable(e.Keyword, e)
+}
+
+func (i *Interpreter) VisitSuperExpression(e *expression.SuperExpression) (interface{}, error) {
+	// "this" always lives in the scope just inside the one holding "super"
+	distance := i.locals[e]
+	superclass := i.environment.getAt(distance, "super")
+	instance := i.environment.getAt(distance-1, "this")
+
+	method, ok := superclass.(*Class).findMethod(e.Method.Lexeme)
+
+	if !ok {
+		return nil, NewRuntimeError(e.Method, fmt.Sprintf("Undefined property '%s'.", e.Method.Lexeme))
+	}
+
+	return method.bind(instance.(*Instance)), nil
+}
+
+func (i *Interpreter) VisitFunctionExpression(e *expression.FunctionExpression) (interface{}, error) {
+	declaration := statement.NewFunctionStatement(e.Keyword, e.Arguments, e.Body.([]statement.Statement))
+	return NewFunction(declaration, i.environment), nil
 }
 
 func (i *Interpreter) VisitLogicalExpression(e *expression.LogicalExpression) (interface{}, error) {
@@ -234,11 +719,11 @@ func (i *Interpreter) VisitLogicalExpression(e *expression.LogicalExpression) (i
 	}
 
 	if e.Operator.Variant == tokens.TokenOr {
-		if i.isTruthy(left) {
+		if IsTruthy(left) {
 			return left, nil
 		}
 	} else {
-		if i.isTruthy(left) {
+		if !IsTruthy(left) {
 			return left, nil
 		}
 	}
@@ -253,17 +738,22 @@ func (i *Interpreter) VisitAssignExpression(e *expression.AssignExpression) (int
 		return nil, err
 	}
 
-	err = i.environment.assign(e.Name, value)
+	if distance, ok := i.locals[e]; ok {
+		i.environment.assignAt(distance, e.Name, value)
+		return value, nil
+	}
+
+	err = i.globals.assign(e.Name, value)
 
 	if err != nil {
 		return nil, err
 	}
 
-	return nil, nil
+	return value, nil
 }
 
 func (i *Interpreter) VisitVariableExpression(e *expression.VariableExpression) (interface{}, error) {
-	value, err := i.environment.get(e.Name)
+	value, err := i.lookUpVariable(e.Name, e)
 
 	if err != nil {
 		return nil, err
@@ -277,7 +767,7 @@ func (i *Interpreter) VisitLiteralExpression(e *expression.LiteralExpression) (i
 }
 
 func (i *Interpreter) VisitGroupingExpression(e *expression.GroupingExpression) (interface{}, error) {
-	return i.evaluate(e)
+	return i.evaluate(e.Expression.(expression.Expression))
 }
 
 func (i *Interpreter) VisitBinaryExpression(e *expression.BinaryExpression) (interface{}, error) {
@@ -293,69 +783,24 @@ func (i *Interpreter) VisitBinaryExpression(e *expression.BinaryExpression) (int
 		return nil, err
 	}
 
-	if e.Operator.Variant != tokens.TokenPlus {
-		err := i.checkBinaryNumberOperands(e.Operator, left, right)
-
-		if err != nil {
-			return nil, err
-		}
-	}
-
 	switch e.Operator.Variant {
-	case tokens.TokenMinus:
-		return left.(float64) - right.(float64), nil
-	case tokens.TokenSlash:
-		// Handle divide by zero
-		if right.(float64) == 0 {
-			return nil, newRuntimeError(e.Operator, "error! attempted to divide by zero")
-		}
-
-		return left.(float64) / right.(float64), nil
-	case tokens.TokenStar:
-		return left.(float64) * right.(float64), nil
-	case tokens.TokenPlus:
-		if reflect.TypeOf(left).String() == "string" && reflect.TypeOf(right).String() != "string" {
-			//TODO(@jparr721) - We should add custom checks for various types
-			// Rough conversion of right value
-			rstr := fmt.Sprintf("%v", right)
-			return left.(string) + rstr, nil
-		}
-
-		if reflect.TypeOf(left).String() == "string" && reflect.TypeOf(right).String() == "string" {
-			return left.(string) + right.(string), nil
-		}
-
-		if reflect.TypeOf(left).String() == "float64" && reflect.TypeOf(right).String() == "float64" {
-			return left.(float64) + right.(float64), nil
-		}
-
-		return nil, newRuntimeError(e.Operator, "Operator requires two strings or two numbers.")
-	case tokens.TokenGreater:
-		return left.(float64) > right.(float64), nil
-	case tokens.TokenGreaterEqual:
-		return left.(float64) >= right.(float64), nil
-	case tokens.TokenLess:
-		return left.(float64) < right.(float64), nil
-	case tokens.TokenLessEqual:
-		return left.(float64) <= right.(float64), nil
 	case tokens.TokenBangEqual:
-		return !i.isEqual(left, right), nil
+		return !IsEqual(left, right), nil
 	case tokens.TokenEqualEqual:
-		return i.isEqual(left, right), nil
+		return IsEqual(left, right), nil
 	}
 
-	// unreachable
-	return nil, nil
-}
-
-func (i *Interpreter) VisitUnaryExpression(e *expression.UnaryExpression) (interface{}, error) {
-	right, err := i.evaluate(e.Right.(expression.Expression))
+	value, err := Binary(e.Operator.Variant, left, right)
 
 	if err != nil {
-		return nil, err
+		return nil, NewRuntimeError(e.Operator, err.Error())
 	}
 
-	err = i.checkInfixNumberOperand(e.Operator, right)
+	return value, nil
+}
+
+func (i *Interpreter) VisitUnaryExpression(e *expression.UnaryExpression) (interface{}, error) {
+	right, err := i.evaluate(e.Right.(expression.Expression))
 
 	if err != nil {
 		return nil, err
@@ -363,45 +808,17 @@ func (i *Interpreter) VisitUnaryExpression(e *expression.UnaryExpression) (inter
 
 	switch e.Operator.Variant {
 	case tokens.TokenMinus:
-		return -right.(float64), nil
+		value, err := Negate(right)
+
+		if err != nil {
+			return nil, NewRuntimeError(e.Operator, err.Error())
+		}
+
+		return value, nil
 	case tokens.TokenBang:
-		return !i.isTruthy(right), nil
+		return !IsTruthy(right), nil
 	}
 
 	// unreachable
 	return nil, nil
 }
-
-func (i *Interpreter) isEqual(a, b interface{}) bool {
-	return reflect.DeepEqual(a, b)
-}
-
-func (i *Interpreter) checkBinaryNumberOperands(operator tokens.Token, left, right interface{}) *RuntimeError {
-	if reflect.TypeOf(left).String() == "float64" && reflect.TypeOf(right).String() == "float64" {
-		return nil
-	}
-
-	return newRuntimeError(operator, "Operands must be two numbers.")
-}
-
-func (i *Interpreter) checkInfixNumberOperand(operator tokens.Token, operand interface{}) *RuntimeError {
-	if reflect.TypeOf(operand).String() == "float64" {
-		return nil
-	}
-
-	return newRuntimeError(operator, "Operans must be numbers.")
-}
-
-// only nil and false are falsy values, everything else evaluates to truthy
-func (i *Interpreter) isTruthy(value interface{}) bool {
-	if value == nil {
-		return false
-	}
-
-	switch value.(type) {
-	case bool:
-		return value.(bool)
-	default:
-		return true
-	}
-}
@@ -0,0 +1,678 @@
+package interpreter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jparr721/obsidian/internal/parser"
+	"github.com/jparr721/obsidian/internal/resolver"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+type interpretTest struct {
+	Name     string
+	Source   string
+	Expected string
+}
+
+// run interprets src and returns everything it printed
+func run(t *testing.T, src string) (string, error) {
+	return interpret(t, NewInterpreter(), src)
+}
+
+// interpret runs src on i, whose globals persist between calls, and returns what it printed
+func interpret(t *testing.T, i *Interpreter, src string) (string, error) {
+	toks, tokErr := tokens.NewTokenizer(src).ScanTokens()
+	if tokErr != nil {
+		t.Fatalf("failed to tokenize test source: %v", tokErr)
+	}
+
+	statements, parseErrs := parser.NewParser(toks).Parse()
+	if len(parseErrs) > 0 {
+		t.Fatalf("failed to parse test source: %v", parseErrs)
+	}
+
+	locals, resolveErrs := resolver.NewResolver().Resolve(statements)
+	if len(resolveErrs) > 0 {
+		t.Fatalf("failed to resolve test source: %v", resolveErrs[0])
+	}
+
+	out := &bytes.Buffer{}
+	i.Resolve(locals)
+	i.SetOutput(out)
+	err := i.Interpret(statements)
+
+	return out.String(), err
+}
+
+func runTests(t *testing.T, tests []interpretTest) {
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+
+		output, err := run(t, test.Source)
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.Name, err)
+			continue
+		}
+
+		if output != test.Expected {
+			t.Errorf("%s: output %q did not match expected output %q", test.Name, output, test.Expected)
+		}
+	}
+}
+
+// runErrorTests expects each source to fail with exactly the Expected error message
+func runErrorTests(t *testing.T, tests []interpretTest) {
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+
+		_, err := run(t, test.Source)
+
+		if err == nil || err.Error() != test.Expected {
+			t.Errorf("%s: error '%v' did not match expected error '%v'", test.Name, err, test.Expected)
+		}
+	}
+}
+
+func TestClosures(t *testing.T) {
+	runTests(t, []interpretTest{
+		{
+			Name: "Counter keeps its own state between calls",
+			Source: `
+				fun makeCounter() {
+					var count = 0;
+					fun increment() {
+						count = count + 1;
+						return count;
+					}
+					return increment;
+				}
+
+				var a = makeCounter();
+				var b = makeCounter();
+				a();
+				a();
+				print a();
+				print b();
+			`,
+			Expected: "3\n1\n",
+		},
+		{
+			Name: "Nested closures see every enclosing scope",
+			Source: `
+				fun outer(x) {
+					fun middle(y) {
+						fun inner(z) {
+							return x + y + z;
+						}
+						return inner;
+					}
+					return middle;
+				}
+
+				print outer(1)(2)(3);
+			`,
+			Expected: "6\n",
+		},
+		{
+			Name: "Closures share the variable they capture",
+			Source: `
+				var get;
+				var set;
+				fun pair() {
+					var value = "before";
+					fun getter() { return value; }
+					fun setter(v) { value = v; }
+					get = getter;
+					set = setter;
+				}
+
+				pair();
+				set("after");
+				print get();
+			`,
+			Expected: "after\n",
+		},
+		{
+			Name: "Closures created inside a loop capture that iteration's locals",
+			Source: `
+				var first;
+				var last;
+				for (var i = 0; i < 3; i = i + 1) {
+					var captured = i;
+					fun show() { return captured; }
+					if (i == 0) first = show;
+					last = show;
+				}
+
+				print first();
+				print last();
+			`,
+			Expected: "0\n2\n",
+		},
+		{
+			Name: "Bound methods keep this inside nested functions",
+			Source: `
+				class Greeter {
+					init(name) { this.name = name; }
+					greeter() {
+						fun greet() { return "hi " + this.name; }
+						return greet;
+					}
+				}
+
+				print Greeter("ada").greeter()();
+			`,
+			Expected: "hi ada\n",
+		},
+	})
+}
+
+func TestLexicalScoping(t *testing.T) {
+	runTests(t, []interpretTest{
+		{
+			Name: "Closures keep the binding they saw at declaration",
+			Source: `
+				var a = "global";
+				{
+					fun show() { print a; }
+					show();
+					var a = "block";
+					show();
+					print a;
+				}
+			`,
+			Expected: "global\nglobal\nblock\n",
+		},
+		{
+			Name: "Assignment updates the resolved scope",
+			Source: `
+				var a = 1;
+				{
+					var a = 2;
+					a = 3;
+					print a;
+				}
+				print a;
+			`,
+			Expected: "3\n1\n",
+		},
+	})
+}
+
+func TestAnonymousFunctions(t *testing.T) {
+	runTests(t, []interpretTest{
+		{
+			Name: "Anonymous functions can be passed as callbacks",
+			Source: `
+				fun apply(f, value) { return f(value); }
+				print apply(fun (n) { return n * 2; }, 21);
+			`,
+			Expected: "42\n",
+		},
+		{
+			Name: "Functions can return anonymous functions",
+			Source: `
+				fun adder(n) {
+					return fun (m) { return n + m; };
+				}
+				var addTwo = adder(2);
+				print addTwo(40);
+				print addTwo;
+			`,
+			Expected: "42\n<fn anonymous>\n",
+		},
+		{
+			Name:     "Anonymous functions can be called immediately",
+			Source:   `fun () { print "called"; }();`,
+			Expected: "called\n",
+		},
+	})
+}
+
+func TestLists(t *testing.T) {
+	runTests(t, []interpretTest{
+		{
+			Name: "Literals, reads and writes",
+			Source: `
+				var xs = [1, "two", [3]];
+				print xs;
+				print xs[1];
+				xs[0] = xs[0] + 10;
+				print xs[0];
+				print xs[2][0];
+				print [];
+			`,
+			Expected: "[1, \"two\", [3]]\ntwo\n11\n3\n[]\n",
+		},
+		{
+			Name: "Natives",
+			Source: `
+				var xs = [1, 2, 3];
+				print push(xs, 4);
+				print pop(xs);
+				insert(xs, 0, 0);
+				insert(xs, len(xs), 9);
+				print xs;
+				print slice(xs, 1, 3);
+				print len(xs);
+			`,
+			Expected: "4\n4\n[0, 1, 2, 3, 9]\n[1, 2]\n5\n",
+		},
+		{
+			Name: "Lists are shared by reference",
+			Source: `
+				var a = [1];
+				var b = a;
+				push(b, 2);
+				print a;
+			`,
+			Expected: "[1, 2]\n",
+		},
+	})
+}
+
+func TestListErrors(t *testing.T) {
+	tests := []interpretTest{
+		{Name: "Reading past the end", Source: "var xs = [1];\nprint xs[1];", Expected: "RuntimeError: [line 2] Index 1 out of bounds for length 1."},
+		{Name: "Writing a negative index", Source: "var xs = [1];\nxs[-1] = 2;", Expected: "RuntimeError: [line 2] Index -1 out of bounds for length 1."},
+		{Name: "Fractional index", Source: "print [1][0.5];", Expected: "RuntimeError: [line 1] Index must be a whole number but got 0.5."},
+		{Name: "Popping an empty list", Source: "pop([]);", Expected: "RuntimeError: [line 1] pop() called on an empty list."},
+		{Name: "Indexing a non list", Source: "print 1[0];", Expected: "RuntimeError: [line 1] Only lists and maps can be indexed."},
+	}
+
+	runErrorTests(t, tests)
+}
+
+func TestOperators(t *testing.T) {
+	runTests(t, []interpretTest{
+		{
+			Name:     "Equality works on any values",
+			Source:   `print nil == nil; print 1 == "1"; print [1, 2] == [1, 2]; print nil != false;`,
+			Expected: "true\nfalse\ntrue\ntrue\n",
+		},
+		{
+			Name:     "Logical and unary operators",
+			Source:   `print !true; print !nil; print -(1 + 2); print nil and 1; print 1 and 2; print nil or "x";`,
+			Expected: "false\ntrue\n-3\nnil\n2\nx\n",
+		},
+		{
+			Name:     "Strings concatenate with any right operand",
+			Source:   `print "a" + 1.5; print "b" + nil; print "c" + [1];`,
+			Expected: "a1.5\nbnil\nc[1]\n",
+		},
+	})
+}
+
+func TestMaps(t *testing.T) {
+	runTests(t, []interpretTest{
+		{
+			Name: "Literals, reads and writes",
+			Source: `
+				var m = {"a": 1, 2: "two", true: nil,};
+				print m;
+				print m["a"];
+				print m[2];
+				m["a"] = m["a"] + 1;
+				m[nil] = [1];
+				print m;
+				print {};
+			`,
+			Expected: "{\"a\": 1, 2: \"two\", true: nil}\n1\ntwo\n{\"a\": 2, 2: \"two\", true: nil, nil: [1]}\n{}\n",
+		},
+		{
+			Name: "Natives iterate in insertion order",
+			Source: `
+				var m = {"b": 2, "a": 1, "c": 3};
+				print delete(m, "a");
+				print delete(m, "a");
+				print has(m, "b");
+				print has(m, "a");
+				var ks = keys(m);
+				for (var i = 0; i < len(ks); i = i + 1) {
+					print ks[i] + "=" + m[ks[i]];
+				}
+				print values(m);
+			`,
+			Expected: "true\nfalse\ntrue\nfalse\nb=2\nc=3\n[2, 3]\n",
+		},
+	})
+}
+
+func TestMapErrors(t *testing.T) {
+	tests := []interpretTest{
+		{Name: "Missing key", Source: "var m = {};\nprint m[\"x\"];", Expected: "RuntimeError: [line 2] Key \"x\" not found."},
+		{Name: "Unhashable literal key", Source: "var m = {[1]: 2};", Expected: "RuntimeError: [line 1] Map keys must be strings, numbers, booleans or nil but got [1]."},
+		{Name: "Unhashable assigned key", Source: "var m = {};\nm[{}] = 1;", Expected: "RuntimeError: [line 2] Map keys must be strings, numbers, booleans or nil but got {}."},
+	}
+
+	runErrorTests(t, tests)
+}
+
+func TestStrings(t *testing.T) {
+	runTests(t, []interpretTest{
+		{
+			Name: "Searching and slicing count characters",
+			Source: `
+				var s = "héllo world";
+				print len(s);
+				print substr(s, 1, 5);
+				print indexOf(s, "world");
+				print indexOf(s, "x");
+				print chars("héy");
+			`,
+			Expected: "11\néllo\n6\n-1\n[\"h\", \"é\", \"y\"]\n",
+		},
+		{
+			Name: "Splitting and joining",
+			Source: `
+				print split("a,b,,c", ",");
+				print split("abc", "");
+				print join(["a", 1, nil], "-");
+				print join([], ", ");
+			`,
+			Expected: "[\"a\", \"b\", \"\", \"c\"]\n[\"a\", \"b\", \"c\"]\na-1-nil\n\n",
+		},
+		{
+			Name: "Case, whitespace and replacement",
+			Source: `
+				print upper("MiXed");
+				print lower("MiXed");
+				print "[" + trim("  padded   ") + "]";
+				print replace("a-b-c", "-", "+");
+				print startsWith("obsidian", "obs");
+				print endsWith("obsidian", "obs");
+				print repeat("ab", 3);
+				print "[" + repeat("ab", 0) + "]";
+			`,
+			Expected: "MIXED\nmixed\n[padded]\na+b+c\ntrue\nfalse\nababab\n[]\n",
+		},
+		{
+			Name: "Formatting",
+			Source: `
+				print format("{} + {} = {}", 1, 2, 1 + 2);
+				print format("{{}} {}", [1]);
+				print format("plain");
+			`,
+			Expected: "1 + 2 = 3\n{} [1]\nplain\n",
+		},
+	})
+}
+
+func TestStringErrors(t *testing.T) {
+	tests := []interpretTest{
+		{Name: "Wrong argument type", Source: "var n = 1;\nprint upper(n);", Expected: "RuntimeError: [line 2] upper() expects a string but got 1."},
+		{Name: "Second argument checked", Source: "print split(\"a\", nil);", Expected: "RuntimeError: [line 1] split() expects a string but got nil."},
+		{Name: "Substring out of range", Source: "print substr(\"abc\", 1, 4);", Expected: "RuntimeError: [line 1] Index 4 out of bounds for length 3."},
+		{Name: "Negative repeat", Source: "print repeat(\"a\", -1);", Expected: "RuntimeError: [line 1] repeat() count must be a whole number of at least 0 but got -1."},
+		{Name: "Joining a non list", Source: "print join(\"abc\", \"\");", Expected: "RuntimeError: [line 1] join() expects a list but got abc."},
+		{Name: "Too few format values", Source: "print format(\"{} {}\", 1);", Expected: "RuntimeError: [line 1] format() has more placeholders than the 1 values given."},
+		{Name: "Too many format values", Source: "print format(\"{}\", 1, 2);", Expected: "RuntimeError: [line 1] format() was given 2 values but only has 1 placeholders."},
+		{Name: "Format without a template", Source: "print format();", Expected: "RuntimeError: [line 1] format() expects a format string."},
+	}
+
+	runErrorTests(t, tests)
+}
+
+func TestMath(t *testing.T) {
+	runTests(t, []interpretTest{
+		{
+			Name:     "Rounding returns whole numbers",
+			Source:   `print floor(2.7); print ceil(2.1); print round(2.5); print round(-2.5); print ceil(-0.5); print abs(-3);`,
+			Expected: "2\n3\n3\n-3\n0\n3\n",
+		},
+		{
+			Name:     "Powers, roots and logarithms",
+			Source:   `print sqrt(16); print pow(2, 10); print log(E); print exp(0); print round(log(exp(5)));`,
+			Expected: "4\n1024\n1\n1\n5\n",
+		},
+		{
+			Name:     "Trigonometry",
+			Source:   `print sin(0); print cos(0); print round(sin(PI / 2)); print atan2(0, 1); print round(acos(-1) * 1000);`,
+			Expected: "0\n1\n1\n0\n3142\n",
+		},
+		{
+			Name:     "Min and max take any number of values",
+			Source:   `print min(3, 1, 2); print max(3, 1, 2); print max(-1);`,
+			Expected: "1\n3\n-1\n",
+		},
+		{
+			Name: "Seeded random numbers repeat",
+			Source: `
+				seed(42);
+				var first = [random(), randint(1, 6), randint(1, 6)];
+				seed(42);
+				var second = [random(), randint(1, 6), randint(1, 6)];
+				print first == second;
+				var n = randint(3, 3);
+				print n;
+				var r = random();
+				print r >= 0 and r < 1;
+			`,
+			Expected: "true\n3\ntrue\n",
+		},
+	})
+}
+
+func TestSeedIsPerInterpreter(t *testing.T) {
+	seeded, other := NewInterpreter(), NewInterpreter()
+
+	expected, err := interpret(t, seeded, "seed(7); print random(); print randint(1, 100);")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another interpreter drawing numbers in between must not move the seeded stream
+	if _, err := interpret(t, seeded, "seed(7);"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := interpret(t, other, "random(); randint(1, 100);"); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := interpret(t, seeded, "print random(); print randint(1, 100);")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if output != expected {
+		t.Errorf("expected the seeded values %q but got %q", expected, output)
+	}
+}
+
+func TestMathErrors(t *testing.T) {
+	tests := []interpretTest{
+		{Name: "Wrong argument type", Source: "print floor(\"1.5\");", Expected: "RuntimeError: [line 1] floor() expects a number but got 1.5."},
+		{Name: "Negative square root", Source: "print sqrt(-4);", Expected: "RuntimeError: [line 1] sqrt() of negative number -4."},
+		{Name: "Logarithm of zero", Source: "print log(0);", Expected: "RuntimeError: [line 1] log() expects a number greater than 0 but got 0."},
+		{Name: "Empty min", Source: "print min();", Expected: "RuntimeError: [line 1] min() expects at least one number."},
+		{Name: "Fractional randint bound", Source: "print randint(1, 2.5);", Expected: "RuntimeError: [line 1] randint() expects a whole number but got 2.5."},
+		{Name: "Empty randint range", Source: "print randint(6, 1);", Expected: "RuntimeError: [line 1] randint() low 6 is above high 1."},
+	}
+
+	runErrorTests(t, tests)
+}
+
+func TestNumbers(t *testing.T) {
+	runTests(t, []interpretTest{
+		{
+			Name:     "Integers stay integers",
+			Source:   `print 7 / 2; print -7 / 2; print 7 % 3; print -7 % 3; print 2 * 3 - 1;`,
+			Expected: "3\n-3\n1\n-1\n5\n",
+		},
+		{
+			Name:     "Modulo truncates toward zero",
+			Source:   `print 7 % -3; print -7 % -3; print -7.5 % 2; print 6 % 3;`,
+			Expected: "1\n-1\n-1.5\n0\n",
+		},
+		{
+			Name:     "Bitwise operators",
+			Source:   `print 12 & 10; print 12 | 10; print 12 ^ 10; print 1 << 10; print -16 >> 2; print 1 << 64; print -1 >> 70;`,
+			Expected: "8\n14\n6\n1024\n-4\n0\n-1\n",
+		},
+		{
+			Name:     "Bitwise precedence sits between comparison and addition",
+			Source:   `print 1 | 2 ^ 3 & 4 << 1; print 1 + 2 << 1; print 5 & 3 == 1; print 6 ^ 3 < 4;`,
+			Expected: "3\n6\ntrue\nfalse\n",
+		},
+		{
+			Name:     "Floats promote the other operand",
+			Source:   `print 7 / 2.0; print 1.5 + 1; print 7.5 % 2; print 3 > 2.5; print -(2.5);`,
+			Expected: "3.5\n2.5\n1.5\ntrue\n-2.5\n",
+		},
+		{
+			Name:     "Integers are exact past 2^53",
+			Source:   `print 9007199254740993 - 1; print 9223372036854775807;`,
+			Expected: "9007199254740992\n9223372036854775807\n",
+		},
+		{
+			Name:     "Equality compares values across number types",
+			Source:   `print 1 == 1.0; print [1, 2] == [1.0, 2]; print {1: "a"} == {1.0: "a"}; print 1 == "1";`,
+			Expected: "true\ntrue\ntrue\nfalse\n",
+		},
+		{
+			Name:     "Whole float keys are the same as integer keys",
+			Source:   `var m = {}; m[1.0] = "one"; print m[1]; print has(m, 1.0); print keys(m);`,
+			Expected: "one\ntrue\n[1]\n",
+		},
+		{
+			Name:     "Conversions",
+			Source:   `print int(2.9); print int(-2.9); print int(" 42 "); print float(3) / 2; print float("1.25"); print int(5);`,
+			Expected: "2\n-2\n42\n1.5\n1.25\n5\n",
+		},
+		{
+			Name:     "Natives keep integer results",
+			Source:   `print len("abc") / 2; print floor(2.5) / 2; print pow(2, 62); print pow(2, -1); print abs(-4); print min(3, 1.5);`,
+			Expected: "1\n1\n4611686018427387904\n0.5\n4\n1.5\n",
+		},
+	})
+}
+
+func TestNumberErrors(t *testing.T) {
+	tests := []interpretTest{
+		{Name: "Addition overflow", Source: "print 9223372036854775807 + 1;", Expected: "RuntimeError: [line 1] Integer overflow."},
+		{Name: "Subtraction overflow", Source: "print -9223372036854775807 - 2;", Expected: "RuntimeError: [line 1] Integer overflow."},
+		{Name: "Multiplication overflow", Source: "print 4294967296 * 4294967296;", Expected: "RuntimeError: [line 1] Integer overflow."},
+		{Name: "Power overflow", Source: "print pow(2, 63);", Expected: "RuntimeError: [line 1] Integer overflow."},
+		{Name: "Negation overflow", Source: "var n = -9223372036854775807 - 1;\nprint -n;", Expected: "RuntimeError: [line 2] Integer overflow."},
+		{Name: "Integer division by zero", Source: "print 1 / 0;", Expected: "RuntimeError: [line 1] error! attempted to divide by zero"},
+		{Name: "Modulo by zero", Source: "print 1 % 0;", Expected: "RuntimeError: [line 1] error! attempted to take a modulo by zero"},
+		{Name: "Float modulo by zero", Source: "print 1.5 % 0;", Expected: "RuntimeError: [line 1] error! attempted to take a modulo by zero"},
+		{Name: "Bitwise operators on floats", Source: "print 1.0 & 1;", Expected: "RuntimeError: [line 1] Operands must be two integers."},
+		{Name: "Bitwise operators on strings", Source: "print \"a\" | 1;", Expected: "RuntimeError: [line 1] Operands must be two integers."},
+		{Name: "Negative shift", Source: "print 1 << -1;", Expected: "RuntimeError: [line 1] Shift count must not be negative but got -1."},
+		{Name: "Modulo of non numbers", Source: "print \"a\" % 2;", Expected: "RuntimeError: [line 1] Operands must be two numbers."},
+		{Name: "Unparseable integer", Source: "print int(\"1.5\");", Expected: "RuntimeError: [line 1] int() cannot convert \"1.5\" to an integer."},
+		{Name: "Float out of integer range", Source: "print int(pow(2.0, 63));", Expected: "RuntimeError: [line 1] int() cannot convert 9223372036854776000 to an integer."},
+		{Name: "Converting a list", Source: "print float([]);", Expected: "RuntimeError: [line 1] float() expects a number or string but got []."},
+	}
+
+	runErrorTests(t, tests)
+}
+
+func TestInterpolation(t *testing.T) {
+	runTests(t, []interpretTest{
+		{
+			Name:     "Expressions are stringified in place",
+			Source:   `var x = 2; print "x = ${x}, half = ${x / 2.0}, nil = ${nil}";`,
+			Expected: "x = 2, half = 1, nil = nil\n",
+		},
+		{
+			Name:     "Nested quotes and braces",
+			Source:   `var m = {"k": "v"}; print "${m["k"]} ${ {"a": [1]}["a"] } ${"inner ${1 + 1}"}";`,
+			Expected: "v [1] inner 2\n",
+		},
+		{
+			Name:     "Adjacent and escaped interpolations",
+			Source:   `var a = "A"; print "${a}${a}"; print "\${a}"; print "$a";`,
+			Expected: "AA\n${a}\n$a\n",
+		},
+		{
+			Name:     "Closures and calls",
+			Source:   `fun greet(name) { return "hi ${name}"; } print "${greet("bo")}!";`,
+			Expected: "hi bo!\n",
+		},
+	})
+}
+
+func TestInterpolationErrors(t *testing.T) {
+	tests := []interpretTest{
+		{Name: "Errors inside an interpolation", Source: "var s = \"a\";\nprint \"${-s}\";", Expected: "RuntimeError: [line 2] Operans must be numbers."},
+		{Name: "Undefined names", Source: "print \"${missing}\";", Expected: "RuntimeError: [line 1] Undefined variable 'missing'"},
+	}
+
+	runErrorTests(t, tests)
+}
+
+func TestExceptions(t *testing.T) {
+	runTests(t, []interpretTest{
+		{
+			Name:     "Thrown errors are caught with their message and line",
+			Source:   "try {\n  throw Error(\"boom\");\n} catch (e) {\n  print e.message;\n  print e.line;\n}",
+			Expected: "boom\n2\n",
+		},
+		{
+			Name:     "Runtime errors are caught as error values",
+			Source:   `try { print -"a"; } catch (e) { print e; print e.value; }`,
+			Expected: "Error: Operans must be numbers.\nnil\n",
+		},
+		{
+			Name:     "Thrown values are kept",
+			Source:   `try { throw [1, 2]; } catch (e) { print e.value; print e.message; }`,
+			Expected: "[1, 2]\n[1, 2]\n",
+		},
+		{
+			Name:     "The stack lists each call innermost last",
+			Source:   "fun a() { throw \"x\"; }\nfun b() { a(); }\ntry { b(); } catch (e) { print e.stack; }",
+			Expected: "[\"<script> at 3:9\", \"b at 2:13\", \"a at 1:11\"]\n",
+		},
+		{
+			Name:     "finally runs after returns and errors",
+			Source:   `fun f() { try { return 1; } finally { print "f"; } } print f(); try { try { throw "e"; } finally { print "g"; } } catch (e) {}`,
+			Expected: "f\n1\ng\n",
+		},
+		{
+			Name:     "Errors from finally replace the error in flight",
+			Source:   `try { try { throw "first"; } finally { throw "second"; } } catch (e) { print e.message; }`,
+			Expected: "second\n",
+		},
+	})
+}
+
+func TestExceptionErrors(t *testing.T) {
+	tests := []interpretTest{
+		{Name: "Uncaught throws are runtime errors", Source: "print 1;\nthrow Error(\"oops\");", Expected: "RuntimeError: [line 2] oops"},
+		{Name: "Uncaught values are stringified", Source: "throw 3;", Expected: "RuntimeError: [line 1] 3"},
+		{Name: "Rethrowing keeps the first line", Source: "try {\n  throw \"x\";\n} catch (e) {\n  throw e;\n}", Expected: "RuntimeError: [line 2] x"},
+		{Name: "Errors only have their own properties", Source: "try { throw 1; } catch (e) { print e.name; }", Expected: "RuntimeError: [line 1] Undefined property 'name'."},
+	}
+
+	runErrorTests(t, tests)
+}
+
+func TestLoopControl(t *testing.T) {
+	runTests(t, []interpretTest{
+		{
+			Name:     "break nested in an if leaves the loop",
+			Source:   `var i = 0; while (true) { if (i == 2) { break; } i = i + 1; } print i;`,
+			Expected: "2\n",
+		},
+		{
+			Name:     "continue runs the for increment",
+			Source:   `for (var i = 0; i < 4; i = i + 1) { if (i == 1) continue; print i; }`,
+			Expected: "0\n2\n3\n",
+		},
+		{
+			Name:     "Labels reach outer loops",
+			Source:   `a: for (var i = 0; i < 3; i = i + 1) { for (var j = 0; j < 3; j = j + 1) { if (j > i) continue a; if (i == 2) break a; print "${i}${j}"; } }`,
+			Expected: "00\n10\n11\n",
+		},
+		{
+			Name:     "Bodies without braces",
+			Source:   `for (var i = 0; i < 3; i = i + 1) print i;`,
+			Expected: "0\n1\n2\n",
+		},
+	})
+}
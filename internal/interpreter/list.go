@@ -0,0 +1,194 @@
+package interpreter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Indexable is a collection that supports subscript reads and writes
+type Indexable interface {
+	Index(key interface{}) (interface{}, error)
+	SetIndex(key, value interface{}) error
+}
+
+// List is obsidian's growable array value, lists are shared by reference
+type List struct {
+	Elements []interface{}
+}
+
+func NewList(elements []interface{}) *List {
+	return &List{elements}
+}
+
+func (l *List) String() string {
+	return l.format(make(map[interface{}]bool))
+}
+
+// format renders the list, printing holds the containers already being rendered further out so
+// a list that contains itself shows up as [...] instead of recursing forever
+func (l *List) format(printing map[interface{}]bool) string {
+	if printing[l] {
+		return "[...]"
+	}
+
+	printing[l] = true
+	defer delete(printing, l)
+
+	parts := make([]string, 0, len(l.Elements))
+	for _, element := range l.Elements {
+		parts = append(parts, nestedRepr(element, printing))
+	}
+
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// Index reads the element at key
+func (l *List) Index(key interface{}) (interface{}, error) {
+	position, err := index(key, len(l.Elements), false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return l.Elements[position], nil
+}
+
+// SetIndex overwrites the element at key
+func (l *List) SetIndex(key, value interface{}) error {
+	position, err := index(key, len(l.Elements), false)
+
+	if err != nil {
+		return err
+	}
+
+	l.Elements[position] = value
+	return nil
+}
+
+// repr renders a value nested inside of a collection, strings are quoted to keep them apart
+func repr(value interface{}) string {
+	return nestedRepr(value, make(map[interface{}]bool))
+}
+
+// nestedRepr is repr for a value inside the containers in printing
+func nestedRepr(value interface{}, printing map[interface{}]bool) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case *List:
+		return v.format(printing)
+	case *Map:
+		return v.format(printing)
+	}
+
+	return stringify(value)
+}
+
+// index converts an obsidian number to a position in a sequence of the given length
+func index(value interface{}, length int, allowEnd bool) (int, error) {
+	position, ok := wholeNumber(value)
+
+	if !ok {
+		return 0, fmt.Errorf("Index must be a whole number but got %s.", stringify(value))
+	}
+
+	limit := int64(length)
+	if allowEnd {
+		limit++
+	}
+
+	if position < 0 || position >= limit {
+		return 0, fmt.Errorf("Index %d out of bounds for length %d.", position, length)
+	}
+
+	return int(position), nil
+}
+
+func listArgument(name string, value interface{}) (*List, error) {
+	list, ok := value.(*List)
+
+	if !ok {
+		return nil, fmt.Errorf("%s() expects a list but got %s.", name, stringify(value))
+	}
+
+	return list, nil
+}
+
+// push appends a value and returns the new length
+func push(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	list, err := listArgument("push", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	list.Elements = append(list.Elements, arguments[1])
+	return int64(len(list.Elements)), nil
+}
+
+// pop removes and returns the last value
+func pop(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	list, err := listArgument("pop", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(list.Elements) == 0 {
+		return nil, fmt.Errorf("pop() called on an empty list.")
+	}
+
+	last := list.Elements[len(list.Elements)-1]
+	list.Elements = list.Elements[:len(list.Elements)-1]
+	return last, nil
+}
+
+// insert places a value before position, inserting at the length appends
+func insert(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	list, err := listArgument("insert", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	position, err := index(arguments[1], len(list.Elements), true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	list.Elements = append(list.Elements, nil)
+	copy(list.Elements[position+1:], list.Elements[position:])
+	list.Elements[position] = arguments[2]
+	return nil, nil
+}
+
+// slice copies the elements from start up to but not including end into a new list
+func slice(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	list, err := listArgument("slice", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := index(arguments[1], len(list.Elements), true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := index(arguments[2], len(list.Elements), true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if start > end {
+		return nil, fmt.Errorf("slice() start %d is after end %d.", start, end)
+	}
+
+	elements := make([]interface{}, end-start)
+	copy(elements, list.Elements[start:end])
+	return NewList(elements), nil
+}
@@ -0,0 +1,201 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Map is obsidian's hash map value. Keys keep their insertion order so printing and
+// iterating over keys() is deterministic. Maps are shared by reference.
+type Map struct {
+	keys   []interface{}
+	values map[interface{}]interface{}
+}
+
+func NewMap() *Map {
+	return &Map{make([]interface{}, 0), make(map[interface{}]interface{})}
+}
+
+// Keys returns the map's keys in insertion order
+func (m *Map) Keys() []interface{} {
+	return m.keys
+}
+
+// Hashable reports whether a value can be used as a map key. Once normalized by hashKey, Go's ==
+// on these types agrees with IsEqual, so they can be used as Go map keys directly.
+func Hashable(value interface{}) bool {
+	switch value.(type) {
+	case nil, bool, int64, float64, string:
+		return true
+	}
+
+	return false
+}
+
+// hashKey stores whole floats as integers, so m[1] and m[1.0] are the same entry
+func hashKey(key interface{}) interface{} {
+	if number, ok := key.(float64); ok && fitsInt(number) {
+		return int64(number)
+	}
+
+	return key
+}
+
+func checkKey(key interface{}) error {
+	if !Hashable(key) {
+		return fmt.Errorf("Map keys must be strings, numbers, booleans or nil but got %s.", stringify(key))
+	}
+
+	return nil
+}
+
+// Get returns the value stored under key
+func (m *Map) Get(key interface{}) (interface{}, bool) {
+	value, ok := m.values[hashKey(key)]
+	return value, ok
+}
+
+// Set stores value under key, new keys go to the end of the iteration order
+func (m *Map) Set(key, value interface{}) {
+	key = hashKey(key)
+
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+
+	m.values[key] = value
+}
+
+// Index reads the value under key, failing when the key is missing
+func (m *Map) Index(key interface{}) (interface{}, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	value, ok := m.Get(key)
+
+	if !ok {
+		return nil, fmt.Errorf("Key %s not found.", repr(key))
+	}
+
+	return value, nil
+}
+
+// SetIndex stores value under key, failing when the key is not hashable
+func (m *Map) SetIndex(key, value interface{}) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	m.Set(key, value)
+	return nil
+}
+
+func (m *Map) delete(key interface{}) bool {
+	key = hashKey(key)
+
+	if _, ok := m.values[key]; !ok {
+		return false
+	}
+
+	delete(m.values, key)
+	for n, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:n], m.keys[n+1:]...)
+			break
+		}
+	}
+
+	return true
+}
+
+func (m *Map) String() string {
+	return m.format(make(map[interface{}]bool))
+}
+
+// format renders the map, a map already being rendered further out shows up as {...}
+func (m *Map) format(printing map[interface{}]bool) string {
+	if printing[m] {
+		return "{...}"
+	}
+
+	printing[m] = true
+	defer delete(printing, m)
+
+	parts := make([]string, 0, len(m.keys))
+	for _, key := range m.keys {
+		parts = append(parts, nestedRepr(key, printing)+": "+nestedRepr(m.values[key], printing))
+	}
+
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func mapArgument(name string, value interface{}) (*Map, error) {
+	m, ok := value.(*Map)
+
+	if !ok {
+		return nil, fmt.Errorf("%s() expects a map but got %s.", name, stringify(value))
+	}
+
+	return m, nil
+}
+
+// keys returns a new list of the map's keys in insertion order
+func keys(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	m, err := mapArgument("keys", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make([]interface{}, len(m.keys))
+	copy(elements, m.keys)
+	return NewList(elements), nil
+}
+
+// values returns a new list of the map's values in key insertion order
+func values(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	m, err := mapArgument("values", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make([]interface{}, 0, len(m.keys))
+	for _, key := range m.keys {
+		elements = append(elements, m.values[key])
+	}
+
+	return NewList(elements), nil
+}
+
+// has reports whether the map holds a key
+func has(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	m, err := mapArgument("has", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkKey(arguments[1]); err != nil {
+		return nil, err
+	}
+
+	_, ok := m.Get(arguments[1])
+	return ok, nil
+}
+
+// remove deletes a key, reporting whether it was present
+func remove(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	m, err := mapArgument("delete", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkKey(arguments[1]); err != nil {
+		return nil, err
+	}
+
+	return m.delete(arguments[1]), nil
+}
@@ -0,0 +1,263 @@
+package interpreter
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// math.go implements the math natives and constants
+
+// constants are defined alongside the natives in every global scope
+var constants = map[string]interface{}{
+	"PI": math.Pi,
+	"E":  math.E,
+}
+
+// Constants returns a copy of the builtin constants
+func Constants() map[string]interface{} {
+	values := make(map[string]interface{}, len(constants))
+	for name, value := range constants {
+		values[name] = value
+	}
+
+	return values
+}
+
+// newGenerator backs random and randint for one interpreter, seed replaces it so runs can be
+// reproduced without other interpreters moving the stream
+func newGenerator() *rand.Rand {
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+func numberArgument(name string, value interface{}) (float64, error) {
+	number, ok := asFloat(value)
+
+	if !ok {
+		return 0, fmt.Errorf("%s() expects a number but got %s.", name, stringify(value))
+	}
+
+	return number, nil
+}
+
+func wholeArgument(name string, value interface{}) (int64, error) {
+	number, ok := wholeNumber(value)
+
+	if !ok {
+		return 0, fmt.Errorf("%s() expects a whole number but got %s.", name, stringify(value))
+	}
+
+	return number, nil
+}
+
+// unary wraps a one argument math function as a native
+func unary(name string, fn func(float64) float64) *NativeFunction {
+	return NewNativeFunction(name, 1, func(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+		number, err := numberArgument(name, arguments[0])
+
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(number), nil
+	})
+}
+
+// rounding wraps a function that rounds to a whole number as a native returning an integer.
+// Integers pass through untouched, floats too large for an integer stay floats.
+func rounding(name string, fn func(float64) float64) *NativeFunction {
+	return NewNativeFunction(name, 1, func(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+		if number, ok := arguments[0].(int64); ok {
+			return number, nil
+		}
+
+		number, err := numberArgument(name, arguments[0])
+
+		if err != nil {
+			return nil, err
+		}
+
+		rounded := fn(number)
+		if fitsInt(rounded) {
+			return int64(rounded), nil
+		}
+
+		return rounded, nil
+	})
+}
+
+func abs(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	if number, ok := arguments[0].(int64); ok && number < 0 {
+		return Negate(number)
+	}
+
+	if !IsNumber(arguments[0]) {
+		return nil, fmt.Errorf("abs() expects a number but got %s.", stringify(arguments[0]))
+	}
+
+	if number, ok := arguments[0].(float64); ok {
+		return math.Abs(number), nil
+	}
+
+	return arguments[0], nil
+}
+
+// pow raises an integer to a non negative integer power exactly, anything else is a float
+func pow(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	if base, ok := arguments[0].(int64); ok {
+		if exponent, ok := arguments[1].(int64); ok && exponent >= 0 {
+			return integerPow(base, exponent)
+		}
+	}
+
+	base, err := numberArgument("pow", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	exponent, err := numberArgument("pow", arguments[1])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return math.Pow(base, exponent), nil
+}
+
+// integerPow is exponentiation by squaring, failing when the result overflows
+func integerPow(base, exponent int64) (interface{}, error) {
+	result := int64(1)
+
+	for exponent > 0 {
+		var err error
+
+		if exponent&1 == 1 {
+			if result, err = multiply(result, base); err != nil {
+				return nil, err
+			}
+		}
+
+		exponent >>= 1
+		if exponent > 0 {
+			if base, err = multiply(base, base); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func atan2(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	y, err := numberArgument("atan2", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	x, err := numberArgument("atan2", arguments[1])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return math.Atan2(y, x), nil
+}
+
+func sqrt(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	number, err := numberArgument("sqrt", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if number < 0 {
+		return nil, fmt.Errorf("sqrt() of negative number %s.", stringify(number))
+	}
+
+	return math.Sqrt(number), nil
+}
+
+// log is the natural logarithm
+func log(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	number, err := numberArgument("log", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if number <= 0 {
+		return nil, fmt.Errorf("log() expects a number greater than 0 but got %s.", stringify(number))
+	}
+
+	return math.Log(number), nil
+}
+
+// extreme returns a native picking the argument that compares as order against all the others,
+// -1 for the smallest and 1 for the largest. The winner keeps its number type.
+func extreme(name string, order int) *NativeFunction {
+	return NewNativeFunction(name, Variadic, func(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+		if len(arguments) == 0 {
+			return nil, fmt.Errorf("%s() expects at least one number.", name)
+		}
+
+		var best interface{}
+		for n, argument := range arguments {
+			if !IsNumber(argument) {
+				return nil, fmt.Errorf("%s() expects a number but got %s.", name, stringify(argument))
+			}
+
+			if n == 0 || compareNumbers(argument, best) == order {
+				best = argument
+			}
+		}
+
+		return best, nil
+	})
+}
+
+// seed restarts the random number generator so the following values repeat between runs
+func seed(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	value, err := wholeArgument("seed", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	interpreter.generator = rand.New(rand.NewSource(value))
+	return nil, nil
+}
+
+// random returns a number in [0, 1)
+func random(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	return interpreter.generator.Float64(), nil
+}
+
+// randint returns a whole number between low and high, both included
+func randint(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	low, err := wholeArgument("randint", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	high, err := wholeArgument("randint", arguments[1])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if low > high {
+		return nil, fmt.Errorf("randint() low %d is above high %d.", low, high)
+	}
+
+	// The span only overflows when the range covers nearly every integer
+	span := high - low + 1
+	if span <= 0 {
+		return nil, fmt.Errorf("randint() range %d to %d is too large.", low, high)
+	}
+
+	return low + interpreter.generator.Int63n(span), nil
+}
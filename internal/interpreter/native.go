@@ -2,31 +2,123 @@ package interpreter
 
 import (
 	"fmt"
+	"math"
 	"time"
+	"unicode/utf8"
 )
 
 // native.go implement's obsidian's native function interface
 
-type clockFunction struct{}
+// NativeFunction is a builtin implemented in Go. Plain errors returned by fn are reported as
+// runtime errors at the call site.
+type NativeFunction struct {
+	name  string
+	arity int
+	fn    func(interpreter *Interpreter, arguments []interface{}) (interface{}, error)
+}
+
+// NewNativeFunction wraps a Go function so obsidian code can call it
+func NewNativeFunction(name string, arity int, fn func(*Interpreter, []interface{}) (interface{}, error)) *NativeFunction {
+	return &NativeFunction{name, arity, fn}
+}
 
-func (c *clockFunction) String() string {
-	return "<native fn: 'clock'>"
+// Name returns the global name the native is defined under
+func (n *NativeFunction) Name() string {
+	return n.name
 }
-func (c *clockFunction) Arity() int { return 0 }
-func (c *clockFunction) Call(interpreter Interpreter, arguments ...interface{}) (interface{}, error) {
-	return time.Now(), nil
+
+func (n *NativeFunction) String() string {
+	return fmt.Sprintf("<native fn: '%s'>", n.name)
+}
+
+func (n *NativeFunction) Arity() int { return n.arity }
+
+func (n *NativeFunction) Call(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	return n.fn(interpreter, arguments)
 }
 
-type printFunction struct{}
+// natives are the builtin registry, every interpreter defines them in its global scope
+var natives = []*NativeFunction{
+	NewNativeFunction("clock", 0, clock),
+	NewNativeFunction("len", 1, length),
+	NewNativeFunction("push", 2, push),
+	NewNativeFunction("pop", 1, pop),
+	NewNativeFunction("insert", 3, insert),
+	NewNativeFunction("slice", 3, slice),
+	NewNativeFunction("keys", 1, keys),
+	NewNativeFunction("values", 1, values),
+	NewNativeFunction("has", 2, has),
+	NewNativeFunction("delete", 2, remove),
+	NewNativeFunction("substr", 3, substr),
+	NewNativeFunction("indexOf", 2, indexOf),
+	NewNativeFunction("split", 2, split),
+	NewNativeFunction("join", 2, join),
+	NewNativeFunction("upper", 1, upper),
+	NewNativeFunction("lower", 1, lower),
+	NewNativeFunction("trim", 1, trim),
+	NewNativeFunction("replace", 3, replace),
+	NewNativeFunction("startsWith", 2, startsWith),
+	NewNativeFunction("endsWith", 2, endsWith),
+	NewNativeFunction("repeat", 2, repeat),
+	NewNativeFunction("chars", 1, chars),
+	NewNativeFunction("format", Variadic, format),
+	rounding("floor", math.Floor),
+	rounding("ceil", math.Ceil),
+	rounding("round", math.Round),
+	NewNativeFunction("abs", 1, abs),
+	NewNativeFunction("sqrt", 1, sqrt),
+	NewNativeFunction("pow", 2, pow),
+	extreme("min", -1),
+	extreme("max", 1),
+	unary("sin", math.Sin),
+	unary("cos", math.Cos),
+	unary("tan", math.Tan),
+	unary("asin", math.Asin),
+	unary("acos", math.Acos),
+	unary("atan", math.Atan),
+	NewNativeFunction("atan2", 2, atan2),
+	NewNativeFunction("log", 1, log),
+	unary("exp", math.Exp),
+	NewNativeFunction("seed", 1, seed),
+	NewNativeFunction("random", 0, random),
+	NewNativeFunction("randint", 2, randint),
+	NewNativeFunction("int", 1, toInt),
+	NewNativeFunction("float", 1, toFloat),
+	NewNativeFunction("Error", 1, newError),
+}
+
+// Natives returns a copy of the builtin registry
+func Natives() []*NativeFunction {
+	registry := make([]*NativeFunction, len(natives))
+	copy(registry, natives)
+	return registry
+}
+
+func defineNatives(globals *environment) {
+	for _, native := range natives {
+		globals.define(native.name, native)
+	}
+
+	for name, value := range constants {
+		globals.define(name, value)
+	}
+}
 
-func (p *printFunction) String() string {
-	return "<native fn: 'print'>"
+// clock returns the seconds since the unix epoch
+func clock(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	return float64(time.Now().UnixNano()) / float64(time.Second), nil
 }
 
-func (p *printFunction) Arity() int { return 254 }
-func (p *printFunction) Call(interpreter Interpreter, arguments ...interface{}) (interface{}, error) {
-	template := arguments[0].(string)
+// length returns the number of elements in a list, entries in a map or characters in a string
+func length(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	switch value := arguments[0].(type) {
+	case *List:
+		return int64(len(value.Elements)), nil
+	case *Map:
+		return int64(len(value.keys)), nil
+	case string:
+		return int64(utf8.RuneCountInString(value)), nil
+	}
 
-	fmt.Printf(template, arguments[1:])
-	return nil, nil
+	return nil, fmt.Errorf("len() expects a list, map or string but got %s.", stringify(arguments[0]))
 }
@@ -0,0 +1,322 @@
+package interpreter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+// number.go implements obsidian's two number types. Integers are int64 and floats are float64.
+// Arithmetic on two integers stays an integer and fails on overflow rather than wrapping, any
+// float operand promotes the other side to a float.
+//
+// Division and modulo truncate: a / b rounds toward zero and a % b takes the sign of a, so
+// -7 / 2 is -3 and -7 % 2 is -1, matching Go and C. Floats follow the same rule through
+// math.Mod. The bitwise operators &, |, ^, << and >> only take integers, shifts drop the bits
+// they push out rather than overflowing and >> copies the sign bit.
+
+var (
+	errOverflow       = errors.New("Integer overflow.")
+	errDivideByZero   = errors.New("error! attempted to divide by zero")
+	errModuloByZero   = errors.New("error! attempted to take a modulo by zero")
+	errNumberOperands = errors.New("Operands must be two numbers.")
+	errIntOperands    = errors.New("Operands must be two integers.")
+)
+
+// isBitwise reports whether an operator only works on integers
+func isBitwise(operator tokens.TokenType) bool {
+	switch operator {
+	case tokens.TokenAmpersand, tokens.TokenPipe, tokens.TokenCaret, tokens.TokenLessLess, tokens.TokenGreaterGreater:
+		return true
+	}
+
+	return false
+}
+
+// IsNumber reports whether a value is an integer or a float
+func IsNumber(value interface{}) bool {
+	switch value.(type) {
+	case int64, float64:
+		return true
+	}
+
+	return false
+}
+
+// asFloat widens either number type to a float
+func asFloat(value interface{}) (float64, bool) {
+	switch number := value.(type) {
+	case int64:
+		return float64(number), true
+	case float64:
+		return number, true
+	}
+
+	return 0, false
+}
+
+// fitsInt reports whether a whole float converts to an int64 without losing its value
+func fitsInt(number float64) bool {
+	return number == math.Trunc(number) && number >= math.MinInt64 && number < math.MaxInt64
+}
+
+// wholeNumber returns the integer value of an integer or a float with no fractional part
+func wholeNumber(value interface{}) (int64, bool) {
+	switch number := value.(type) {
+	case int64:
+		return number, true
+	case float64:
+		if fitsInt(number) {
+			return int64(number), true
+		}
+	}
+
+	return 0, false
+}
+
+// Binary applies an arithmetic or comparison operator. Errors are plain, callers pin them to
+// the operator's line.
+func Binary(operator tokens.TokenType, left, right interface{}) (interface{}, error) {
+	if operator == tokens.TokenPlus {
+		if s, ok := left.(string); ok {
+			return s + stringify(right), nil
+		}
+
+		if !IsNumber(left) || !IsNumber(right) {
+			return nil, errors.New("Operator requires two strings or two numbers.")
+		}
+	}
+
+	a, aok := left.(int64)
+	b, bok := right.(int64)
+
+	if aok && bok {
+		return integerBinary(operator, a, b)
+	}
+
+	if isBitwise(operator) {
+		return nil, errIntOperands
+	}
+
+	x, xok := asFloat(left)
+	y, yok := asFloat(right)
+
+	if !xok || !yok {
+		return nil, errNumberOperands
+	}
+
+	return floatBinary(operator, x, y)
+}
+
+func integerBinary(operator tokens.TokenType, a, b int64) (interface{}, error) {
+	switch operator {
+	case tokens.TokenPlus:
+		sum := a + b
+		if (sum > a) != (b > 0) {
+			return nil, errOverflow
+		}
+
+		return sum, nil
+	case tokens.TokenMinus:
+		difference := a - b
+		if (difference < a) != (b > 0) {
+			return nil, errOverflow
+		}
+
+		return difference, nil
+	case tokens.TokenStar:
+		return multiply(a, b)
+	case tokens.TokenSlash, tokens.TokenModulo:
+		if b == 0 && operator == tokens.TokenModulo {
+			return nil, errModuloByZero
+		}
+
+		if b == 0 {
+			return nil, errDivideByZero
+		}
+
+		if a == math.MinInt64 && b == -1 {
+			if operator == tokens.TokenModulo {
+				return int64(0), nil
+			}
+
+			return nil, errOverflow
+		}
+
+		if operator == tokens.TokenModulo {
+			return a % b, nil
+		}
+
+		return a / b, nil
+	case tokens.TokenAmpersand:
+		return a & b, nil
+	case tokens.TokenPipe:
+		return a | b, nil
+	case tokens.TokenCaret:
+		return a ^ b, nil
+	case tokens.TokenLessLess, tokens.TokenGreaterGreater:
+		if b < 0 {
+			return nil, fmt.Errorf("Shift count must not be negative but got %d.", b)
+		}
+
+		if operator == tokens.TokenLessLess {
+			return a << uint64(b), nil
+		}
+
+		return a >> uint64(b), nil
+	case tokens.TokenGreater:
+		return a > b, nil
+	case tokens.TokenGreaterEqual:
+		return a >= b, nil
+	case tokens.TokenLess:
+		return a < b, nil
+	case tokens.TokenLessEqual:
+		return a <= b, nil
+	}
+
+	return nil, fmt.Errorf("Unknown operator %s.", operator)
+}
+
+func floatBinary(operator tokens.TokenType, a, b float64) (interface{}, error) {
+	switch operator {
+	case tokens.TokenPlus:
+		return a + b, nil
+	case tokens.TokenMinus:
+		return a - b, nil
+	case tokens.TokenStar:
+		return a * b, nil
+	case tokens.TokenSlash, tokens.TokenModulo:
+		if b == 0 && operator == tokens.TokenModulo {
+			return nil, errModuloByZero
+		}
+
+		if b == 0 {
+			return nil, errDivideByZero
+		}
+
+		if operator == tokens.TokenModulo {
+			return math.Mod(a, b), nil
+		}
+
+		return a / b, nil
+	case tokens.TokenGreater:
+		return a > b, nil
+	case tokens.TokenGreaterEqual:
+		return a >= b, nil
+	case tokens.TokenLess:
+		return a < b, nil
+	case tokens.TokenLessEqual:
+		return a <= b, nil
+	}
+
+	return nil, fmt.Errorf("Unknown operator %s.", operator)
+}
+
+// multiply returns a * b, failing when the product does not fit in an int64
+func multiply(a, b int64) (int64, error) {
+	if a == 0 || b == 0 {
+		return 0, nil
+	}
+
+	product := a * b
+	if product/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, errOverflow
+	}
+
+	return product, nil
+}
+
+// Negate flips the sign of a number
+func Negate(value interface{}) (interface{}, error) {
+	switch number := value.(type) {
+	case int64:
+		if number == math.MinInt64 {
+			return nil, errOverflow
+		}
+
+		return -number, nil
+	case float64:
+		return -number, nil
+	}
+
+	return nil, errors.New("Operans must be numbers.")
+}
+
+// compareNumbers orders two numbers, integers are compared exactly
+func compareNumbers(left, right interface{}) int {
+	a, aok := left.(int64)
+	b, bok := right.(int64)
+
+	if !aok || !bok {
+		x, _ := asFloat(left)
+		y, _ := asFloat(right)
+
+		// NaN is unordered, it compares as greater so it is never equal to anything
+		switch {
+		case x < y:
+			return -1
+		case x == y:
+			return 0
+		}
+
+		return 1
+	}
+
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// toInt converts a value to an integer, floats are truncated toward zero
+func toInt(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	switch value := arguments[0].(type) {
+	case int64:
+		return value, nil
+	case float64:
+		truncated := math.Trunc(value)
+
+		if !fitsInt(truncated) {
+			return nil, fmt.Errorf("int() cannot convert %s to an integer.", stringify(value))
+		}
+
+		return int64(truncated), nil
+	case string:
+		number, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("int() cannot convert %s to an integer.", repr(value))
+		}
+
+		return number, nil
+	}
+
+	return nil, fmt.Errorf("int() expects a number or string but got %s.", stringify(arguments[0]))
+}
+
+// toFloat converts a value to a float
+func toFloat(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	if number, ok := asFloat(arguments[0]); ok {
+		return number, nil
+	}
+
+	if s, ok := arguments[0].(string); ok {
+		number, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("float() cannot convert %s to a float.", repr(s))
+		}
+
+		return number, nil
+	}
+
+	return nil, fmt.Errorf("float() expects a number or string but got %s.", stringify(arguments[0]))
+}
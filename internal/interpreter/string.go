@@ -0,0 +1,258 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// string.go implements the string natives, positions count characters rather than bytes
+
+func stringArgument(name string, value interface{}) (string, error) {
+	s, ok := value.(string)
+
+	if !ok {
+		return "", fmt.Errorf("%s() expects a string but got %s.", name, stringify(value))
+	}
+
+	return s, nil
+}
+
+// stringArguments checks that every argument is a string
+func stringArguments(name string, arguments []interface{}) ([]string, error) {
+	strs := make([]string, 0, len(arguments))
+
+	for _, argument := range arguments {
+		s, err := stringArgument(name, argument)
+
+		if err != nil {
+			return nil, err
+		}
+
+		strs = append(strs, s)
+	}
+
+	return strs, nil
+}
+
+// substr copies the characters from start up to but not including end
+func substr(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	s, err := stringArgument("substr", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	runes := []rune(s)
+	start, err := index(arguments[1], len(runes), true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := index(arguments[2], len(runes), true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if start > end {
+		return nil, fmt.Errorf("substr() start %d is after end %d.", start, end)
+	}
+
+	return string(runes[start:end]), nil
+}
+
+// indexOf returns the position of the first occurrence of a substring, or -1
+func indexOf(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	strs, err := stringArguments("indexOf", arguments)
+
+	if err != nil {
+		return nil, err
+	}
+
+	position := strings.Index(strs[0], strs[1])
+
+	if position < 0 {
+		return int64(-1), nil
+	}
+
+	return int64(utf8.RuneCountInString(strs[0][:position])), nil
+}
+
+// split breaks a string around each separator, an empty separator splits it into characters
+func split(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	strs, err := stringArguments("split", arguments)
+
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(strs[0], strs[1])
+	elements := make([]interface{}, 0, len(parts))
+
+	for _, part := range parts {
+		elements = append(elements, part)
+	}
+
+	return NewList(elements), nil
+}
+
+// join concatenates the elements of a list with a separator between each
+func join(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	list, err := listArgument("join", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	separator, err := stringArgument("join", arguments[1])
+
+	if err != nil {
+		return nil, err
+	}
+
+	parts := make([]string, 0, len(list.Elements))
+	for _, element := range list.Elements {
+		parts = append(parts, stringify(element))
+	}
+
+	return strings.Join(parts, separator), nil
+}
+
+func upper(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	s, err := stringArgument("upper", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.ToUpper(s), nil
+}
+
+func lower(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	s, err := stringArgument("lower", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.ToLower(s), nil
+}
+
+// trim removes leading and trailing whitespace
+func trim(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	s, err := stringArgument("trim", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.TrimSpace(s), nil
+}
+
+// replace substitutes every occurrence of old with new
+func replace(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	strs, err := stringArguments("replace", arguments)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.Replace(strs[0], strs[1], strs[2], -1), nil
+}
+
+func startsWith(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	strs, err := stringArguments("startsWith", arguments)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.HasPrefix(strs[0], strs[1]), nil
+}
+
+func endsWith(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	strs, err := stringArguments("endsWith", arguments)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.HasSuffix(strs[0], strs[1]), nil
+}
+
+// repeat concatenates count copies of a string
+func repeat(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	s, err := stringArgument("repeat", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	count, ok := wholeNumber(arguments[1])
+
+	if !ok || count < 0 {
+		return nil, fmt.Errorf("repeat() count must be a whole number of at least 0 but got %s.", stringify(arguments[1]))
+	}
+
+	return strings.Repeat(s, int(count)), nil
+}
+
+// chars returns a new list holding each character of a string
+func chars(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	s, err := stringArgument("chars", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make([]interface{}, 0, len(s))
+	for _, r := range s {
+		elements = append(elements, string(r))
+	}
+
+	return NewList(elements), nil
+}
+
+// format replaces each {} in its first argument with the next argument, {{ and }} are literal
+// braces
+func format(interpreter *Interpreter, arguments []interface{}) (interface{}, error) {
+	if len(arguments) == 0 {
+		return nil, fmt.Errorf("format() expects a format string.")
+	}
+
+	template, err := stringArgument("format", arguments[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := arguments[1:]
+	used := 0
+
+	var b strings.Builder
+	for n := 0; n < len(template); n++ {
+		switch {
+		case strings.HasPrefix(template[n:], "{{"), strings.HasPrefix(template[n:], "}}"):
+			b.WriteByte(template[n])
+			n++
+		case strings.HasPrefix(template[n:], "{}"):
+			if used == len(values) {
+				return nil, fmt.Errorf("format() has more placeholders than the %d values given.", len(values))
+			}
+
+			b.WriteString(stringify(values[used]))
+			used++
+			n++
+		default:
+			b.WriteByte(template[n])
+		}
+	}
+
+	if used != len(values) {
+		return nil, fmt.Errorf("format() was given %d values but only has %d placeholders.", len(values), used)
+	}
+
+	return b.String(), nil
+}
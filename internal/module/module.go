@@ -0,0 +1,209 @@
+// Package module finds, parses and caches the files loaded by import statements. Both
+// backends share it, they only differ in how they run a module the first time it is loaded.
+package module
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jparr721/obsidian/internal/diagnostics"
+	"github.com/jparr721/obsidian/internal/expression"
+	"github.com/jparr721/obsidian/internal/parser"
+	"github.com/jparr721/obsidian/internal/resolver"
+	"github.com/jparr721/obsidian/internal/statement"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+// Module is the value an import binds, it reads through to the module's globals
+type Module struct {
+	Name string
+	Path string
+
+	globals map[string]interface{}
+}
+
+// Get returns an exported top level name, names starting with an underscore stay private
+func (m *Module) Get(name string) (interface{}, bool) {
+	if strings.HasPrefix(name, "_") {
+		return nil, false
+	}
+
+	value, ok := m.globals[name]
+	return value, ok
+}
+
+func (m *Module) String() string {
+	return fmt.Sprintf("<module %s>", m.Name)
+}
+
+// Runner executes a module file and returns its globals
+type Runner func(file string) (map[string]interface{}, error)
+
+// Loader resolves import paths and runs each module at most once
+type Loader struct {
+	searchPaths []string
+	modules     map[string]*Module
+
+	// loading is the chain of files currently being imported, for cycle detection
+	loading []string
+
+	// sources collects the text of every module parsed so diagnostics can quote it
+	sources diagnostics.Sources
+}
+
+// NewLoader creates a loader that looks for modules next to the importing file, then in each
+// search path in order
+func NewLoader(searchPaths ...string) *Loader {
+	return &Loader{searchPaths, make(map[string]*Module), make([]string, 0), diagnostics.Sources{}}
+}
+
+// SetSources records the text of every module the loader parses in sources
+func (l *Loader) SetSources(sources diagnostics.Sources) {
+	l.sources = sources
+}
+
+// Errors is every problem found parsing and resolving a module, in the order they were found
+type Errors []error
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Located reports whether err already points into the module it came from. Such errors are
+// passed on as they are rather than being reported at the import.
+func Located(err error) bool {
+	switch err.(type) {
+	case diagnostics.Diagnosable, Errors:
+		return true
+	}
+
+	return false
+}
+
+// Find returns the absolute path of the module file an import in from refers to
+func (l *Loader) Find(from, path string) (string, error) {
+	candidates := []string{path}
+
+	if !filepath.IsAbs(path) {
+		candidates = []string{filepath.Join(filepath.Dir(from), path)}
+		for _, dir := range l.searchPaths {
+			candidates = append(candidates, filepath.Join(dir, path))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return filepath.Abs(candidate)
+		}
+	}
+
+	return "", fmt.Errorf("Module '%s' not found.", path)
+}
+
+// Import returns the module an import in from refers to, calling run the first time it is
+// loaded. Importing a module that is still loading is an import cycle.
+func (l *Loader) Import(from, path string, run Runner) (*Module, error) {
+	file, err := l.Find(from, path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if module, ok := l.modules[file]; ok {
+		return module, nil
+	}
+
+	// The first import comes from the entry file, which starts the chain
+	if len(l.loading) == 0 && from != "" {
+		if entry, err := filepath.Abs(from); err == nil {
+			l.loading = append(l.loading, entry)
+		}
+	}
+
+	for n, loading := range l.loading {
+		if loading == file {
+			return nil, fmt.Errorf("Import cycle: %s.", cycle(append(l.loading[n:], file)))
+		}
+	}
+
+	l.loading = append(l.loading, file)
+	defer func() { l.loading = l.loading[:len(l.loading)-1] }()
+
+	globals, err := run(file)
+
+	if err != nil {
+		if Located(err) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("Error in module '%s': %v", path, err)
+	}
+
+	module := &Module{moduleName(file), file, globals}
+	l.modules[file] = module
+	return module, nil
+}
+
+func cycle(files []string) string {
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, filepath.Base(file))
+	}
+
+	return strings.Join(names, " -> ")
+}
+
+func moduleName(file string) string {
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
+
+// Parse reads, parses and resolves a module file
+func (l *Loader) Parse(file string) ([]statement.Statement, map[expression.Expression]int, error) {
+	src, err := ioutil.ReadFile(file)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	l.sources[file] = string(src)
+
+	tokenizer := tokens.NewTokenizer(string(src))
+	tokenizer.SetFile(file)
+	toks, tokErr := tokenizer.ScanTokens()
+
+	if tokErr != nil {
+		return nil, nil, tokErr
+	}
+
+	statements, parseErrs := parser.NewParser(toks).Parse()
+
+	if len(parseErrs) > 0 {
+		errs := make(Errors, 0, len(parseErrs))
+		for _, err := range parseErrs {
+			errs = append(errs, err)
+		}
+
+		return nil, nil, errs
+	}
+
+	locals, resolveErrs := resolver.NewResolver().Resolve(statements)
+
+	if len(resolveErrs) > 0 {
+		errs := make(Errors, 0, len(resolveErrs))
+		for _, err := range resolveErrs {
+			errs = append(errs, err)
+		}
+
+		return nil, nil, errs
+	}
+
+	return statements, locals, nil
+}
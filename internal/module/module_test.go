@@ -0,0 +1,158 @@
+package module
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/jparr721/obsidian/internal/diagnostics"
+)
+
+// writeModules creates each named file in a fresh directory and returns the directory
+func writeModules(t *testing.T, files ...string) string {
+	dir := t.TempDir()
+
+	for _, file := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, file), []byte(""), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestImportRunsOnce(t *testing.T) {
+	dir := writeModules(t, "main.ob", "util.ob")
+	main := filepath.Join(dir, "main.ob")
+	loader := NewLoader()
+
+	runs := 0
+	run := func(file string) (map[string]interface{}, error) {
+		runs++
+		return map[string]interface{}{"answer": 42.0, "_hidden": true}, nil
+	}
+
+	first, err := loader.Import(main, "util.ob", run)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := loader.Import(main, "./util.ob", run)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if runs != 1 || first != second {
+		t.Errorf("expected the module to run once and be shared, ran %d times", runs)
+	}
+
+	if first.String() != "<module util>" {
+		t.Errorf("unexpected module name %s", first)
+	}
+
+	if value, ok := first.Get("answer"); !ok || value != 42.0 {
+		t.Errorf("expected answer to be exported, got %v", value)
+	}
+
+	if _, ok := first.Get("_hidden"); ok {
+		t.Error("expected names starting with an underscore to stay private")
+	}
+}
+
+func TestFind(t *testing.T) {
+	local := writeModules(t, "main.ob", "near.ob")
+	lib := writeModules(t, "far.ob", "near.ob")
+	main := filepath.Join(local, "main.ob")
+	loader := NewLoader(lib)
+
+	type findTest struct {
+		Name     string
+		Path     string
+		Expected string
+	}
+
+	tests := []findTest{
+		{"Modules next to the importer win", "near.ob", filepath.Join(local, "near.ob")},
+		{"Search paths are used next", "far.ob", filepath.Join(lib, "far.ob")},
+		{"Missing modules are reported", "gone.ob", "Module 'gone.ob' not found."},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+
+		got, err := loader.Find(main, test.Path)
+		if err != nil {
+			got = err.Error()
+		}
+
+		if got != test.Expected {
+			t.Errorf("%s: expected %s but got %s", test.Name, test.Expected, got)
+		}
+	}
+}
+
+func TestImportCycle(t *testing.T) {
+	dir := writeModules(t, "main.ob", "a.ob", "b.ob")
+	main := filepath.Join(dir, "main.ob")
+	loader := NewLoader()
+
+	// a imports b, which imports a again
+	var run Runner
+	run = func(file string) (map[string]interface{}, error) {
+		next := map[string]string{"a.ob": "b.ob", "b.ob": "a.ob"}[filepath.Base(file)]
+		if _, err := loader.Import(file, next, run); err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{}, nil
+	}
+
+	_, err := loader.Import(main, "a.ob", run)
+
+	expected := "Error in module 'a.ob': Error in module 'b.ob': Import cycle: a.ob -> b.ob -> a.ob."
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %q but got %v", expected, err)
+	}
+
+	// A failed import is not cached, so importing again reports the cycle again
+	if _, err := loader.Import(main, "a.ob", run); err == nil {
+		t.Error("expected the cycle to be reported again")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	dir := writeModules(t, "main.ob")
+	main, bad := filepath.Join(dir, "main.ob"), filepath.Join(dir, "bad.ob")
+	src := "var x = ;\nprint y\n"
+
+	if err := ioutil.WriteFile(bad, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sources := diagnostics.Sources{}
+	loader := NewLoader()
+	loader.SetSources(sources)
+
+	run := func(file string) (map[string]interface{}, error) {
+		_, _, err := loader.Parse(file)
+		return nil, err
+	}
+
+	_, err := loader.Import(main, "bad.ob", run)
+	errs, ok := err.(Errors)
+
+	if !ok || len(errs) != 2 {
+		t.Fatalf("expected both parse errors but got %v", err)
+	}
+
+	// Each error still points into the module, whose source is there to quote
+	for _, err := range errs {
+		if d, ok := err.(diagnostics.Diagnosable); !ok || d.Diagnostic().Span.File != bad {
+			t.Errorf("expected an error located in %s but got %v", bad, err)
+		}
+	}
+
+	if sources[bad] != src {
+		t.Errorf("expected the module source to be recorded but got %q", sources[bad])
+	}
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 
+	"github.com/jparr721/obsidian/internal/diagnostics"
 	"github.com/jparr721/obsidian/internal/tokens"
 )
 
@@ -26,7 +27,12 @@ func (p *ParseError) Error() string {
 		pos = "at '" + p.token.Lexeme + "'"
 	}
 
-	return fmt.Sprintf("ParseError: [line %d] Error %s: %s\n", p.token.Line, pos, p.message)
+	return fmt.Sprintf("ParseError: [line %d] Error %s: %s", p.token.Line, pos, p.message)
+}
+
+// Diagnostic points at the token the parser could not make sense of
+func (p *ParseError) Diagnostic() *diagnostics.Diagnostic {
+	return &diagnostics.Diagnostic{Code: diagnostics.CodeParse, Message: p.message, Span: p.token.Span()}
 }
 
 // ReportParseError geneerates the proper error formatting from a given error type
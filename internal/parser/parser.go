@@ -8,16 +8,17 @@ import (
 	"github.com/jparr721/obsidian/internal/tokens"
 )
 
-const (
-	inLoopStatement    = true
-	notInLoopStatement = false
-)
-
 // Parser represents the Obsidian recurisve descent parser
 type Parser struct {
 	tokens  []tokens.Token
 	current int
-	inLoop  bool
+
+	// loops holds the label of each loop being parsed, innermost last. Unlabeled loops have an
+	// empty label and function bodies start with none.
+	loops []tokens.Token
+
+	// errors holds every error found so far, parsing carries on past them
+	errors []*ParseError
 }
 
 // NewParser creates a new parsing object for a list of tokens
@@ -25,27 +26,31 @@ func NewParser(tokens []tokens.Token) *Parser {
 	return &Parser{
 		tokens:  tokens,
 		current: 0,
+		errors:  make([]*ParseError, 0),
 	}
 }
 
-// Parse initiates the recurisve descent parser on a supplied list of tokens
-// this function returns a ParseError in the event that something fails
-func (p *Parser) Parse() ([]statement.Statement, *ParseError) {
+// Parse initiates the recurisve descent parser on a supplied list of tokens. Errors don't stop
+// it, broken declarations are skipped and the statements that did parse are returned alongside
+// every error found.
+func (p *Parser) Parse() ([]statement.Statement, []*ParseError) {
 	statements := make([]statement.Statement, 0)
 
 	for !p.end() {
-		statement, err := p.declaration()
+		errors := len(p.errors)
 
-		// Stop parsing - report the error
-		if err != nil {
-			ReportParseError(err)
-			return nil, err
+		if statement := p.recoveringDeclaration(); statement != nil {
+			statements = append(statements, statement)
 		}
 
-		statements = append(statements, statement)
+		// A declaration that broke inside its braces can leave the closing one behind, it
+		// belongs to the declaration already reported rather than starting a new statement
+		if len(p.errors) > errors && p.check(tokens.TokenCsquiggle) {
+			p.next()
+		}
 	}
 
-	return statements, nil
+	return statements, p.errors
 }
 
 func (p *Parser) check(tType tokens.TokenType) bool {
@@ -72,6 +77,14 @@ func (p *Parser) peek() tokens.Token {
 	return p.tokens[p.current]
 }
 
+func (p *Parser) peekNext() tokens.Token {
+	if p.end() {
+		return p.peek()
+	}
+
+	return p.tokens[p.current+1]
+}
+
 func (p *Parser) prev() tokens.Token {
 	return p.tokens[p.current-1]
 }
@@ -87,25 +100,122 @@ func (p *Parser) match(tTypes ...tokens.TokenType) bool {
 	return false
 }
 
-// declaration -> varDecl | statement;
+// recoveringDeclaration parses a declaration, when it is broken the error is recorded, the rest
+// of it skipped and nil returned in its place
+func (p *Parser) recoveringDeclaration() statement.Statement {
+	start := p.current
+	declaration, err := p.declaration()
+
+	if err == nil {
+		return declaration
+	}
+
+	p.errors = append(p.errors, err)
+
+	// Always make progress, a declaration can fail on its first token
+	if p.current == start {
+		p.next()
+	}
+
+	p.synchronize()
+	return nil
+}
+
+// declaration -> classDecl | funDecl | varDecl | statement;
 func (p *Parser) declaration() (statement.Statement, *ParseError) {
-	if p.match(tokens.TokenFun) {
+	if p.match(tokens.TokenClass) {
+		return p.classDeclaration()
+	}
+	if p.match(tokens.TokenImport) {
+		return p.importDeclaration()
+	}
+	// A bare "fun (" starts an anonymous function expression instead
+	if p.check(tokens.TokenFun) && p.peekNext().Variant == tokens.TokenIdentifier {
+		p.next()
 		return p.function("function")
 	}
 	if p.match(tokens.TokenVar) {
-		value, err := p.varDeclaration()
+		return p.varDeclaration()
+	}
+
+	return p.statement()
+}
+
+// importDecl -> "import" string "as" identifier ";" ;
+func (p *Parser) importDeclaration() (statement.Statement, *ParseError) {
+	keyword := p.prev()
+	path, err := p.consume(tokens.TokenString, "Expected a module path string after 'import'.")
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(tokens.TokenAs, "Expected 'as' after module path.")
+
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := p.consume(tokens.TokenIdentifier, "Expected module name after 'as'.")
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(tokens.TokenSemi, "Expected ';' after import.")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return statement.NewImportStatement(keyword, path, name), nil
+}
+
+// classDecl -> "class" identifier ( "<" identifier )? "{" function* "}";
+func (p *Parser) classDeclaration() (statement.Statement, *ParseError) {
+	name, err := p.consume(tokens.TokenIdentifier, "Expected class name.")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var superclass *expression.VariableExpression
+
+	if p.match(tokens.TokenLess) {
+		superName, err := p.consume(tokens.TokenIdentifier, "Expected superclass name.")
 
 		if err != nil {
-			// unwind errors to next valid expression.
-			p.synchronize()
-			// Bubble up error regardless.
 			return nil, err
 		}
 
-		return value, nil
+		superclass = expression.NewVariableExpression(superName)
 	}
 
-	return p.statement()
+	_, err = p.consume(tokens.TokenOsquiggle, "Expected '{' before class body.")
+
+	if err != nil {
+		return nil, err
+	}
+
+	methods := make([]*statement.FunctionStatement, 0)
+
+	for !p.check(tokens.TokenCsquiggle) && !p.end() {
+		method, err := p.function("method")
+
+		if err != nil {
+			return nil, err
+		}
+
+		methods = append(methods, method.(*statement.FunctionStatement))
+	}
+
+	_, err = p.consume(tokens.TokenCsquiggle, "Expected '}' after class body.")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return statement.NewClassStatement(name, superclass, methods), nil
 }
 
 // return -> "return" expression? ";";
@@ -123,7 +233,10 @@ func (p *Parser) ret() (statement.Statement, *ParseError) {
 		}
 	}
 
-	p.consume(tokens.TokenSemi, "Expected ';' after return value")
+	if _, err = p.consume(tokens.TokenSemi, "Expected ';' after return value"); err != nil {
+		return nil, err
+	}
+
 	return statement.NewReturnStatement(keyword, value), nil
 }
 
@@ -141,18 +254,31 @@ func (p *Parser) function(kind string) (statement.Statement, *ParseError) {
 		return nil, err
 	}
 
+	arguments, body, err := p.functionBody(kind)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return statement.NewFunctionStatement(name, arguments, body), nil
+}
+
+// functionBody -> parameters ")" block; shared by declarations and anonymous functions
+func (p *Parser) functionBody(kind string) ([]tokens.Token, []statement.Statement, *ParseError) {
+	var err *ParseError
+
 	arguments := make([]tokens.Token, 0)
 
 	if !p.check(tokens.TokenCparen) {
 		for remainingArgs := true; remainingArgs; remainingArgs = p.match(tokens.TokenComma) {
 			if len(arguments) >= 255 {
-				return nil, newParseError(p.peek(), "A function cannot have more than 255 arguments.")
+				return nil, nil, newParseError(p.peek(), "A function cannot have more than 255 arguments.")
 			}
 
 			arg, err := p.consume(tokens.TokenIdentifier, "Expected argument name.")
 
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
 			arguments = append(arguments, arg)
@@ -161,22 +287,27 @@ func (p *Parser) function(kind string) (statement.Statement, *ParseError) {
 	_, err = p.consume(tokens.TokenCparen, "Expected ')' after argument list.")
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	_, err = p.consume(tokens.TokenOsquiggle, fmt.Sprintf("Expected '{' before %s body.", kind))
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	// break and continue can't reach loops outside of the function
+	enclosing := p.loops
+	p.loops = nil
+	defer func() { p.loops = enclosing }()
+
 	body, err := p.block()
 
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return statement.NewFunctionStatement(name, arguments, body), nil
+	return arguments, body, nil
 }
 
 func (p *Parser) varDeclaration() (statement.Statement, *ParseError) {
@@ -195,15 +326,22 @@ func (p *Parser) varDeclaration() (statement.Statement, *ParseError) {
 		}
 	}
 
-	p.consume(tokens.TokenSemi, "Expected ';' after variable declaration")
+	if _, err = p.consume(tokens.TokenSemi, "Expected ';' after variable declaration"); err != nil {
+		return nil, err
+	}
+
 	variableStatement := statement.NewVariableStatement(name, initializer)
 	return variableStatement, nil
 }
 
 // statement -> statement.StatementStatement | printStatement;
 func (p *Parser) statement() (statement.Statement, *ParseError) {
+	if p.check(tokens.TokenIdentifier) && p.peekNext().Variant == tokens.TokenColon {
+		return p.labeledStatement()
+	}
+
 	if p.match(tokens.TokenFor) {
-		return p.forStatement()
+		return p.forStatement(tokens.Token{})
 	}
 
 	if p.match(tokens.TokenIf) {
@@ -219,11 +357,23 @@ func (p *Parser) statement() (statement.Statement, *ParseError) {
 	}
 
 	if p.match(tokens.TokenWhile) {
-		return p.whileStatement()
+		return p.whileStatement(tokens.Token{})
 	}
 
 	if p.match(tokens.TokenBreak) {
-		return p.breakStatement()
+		return p.loopControl("break")
+	}
+
+	if p.match(tokens.TokenContinue) {
+		return p.loopControl("continue")
+	}
+
+	if p.match(tokens.TokenThrow) {
+		return p.throwStatement()
+	}
+
+	if p.match(tokens.TokenTry) {
+		return p.tryStatement()
 	}
 
 	if p.match(tokens.TokenOsquiggle) {
@@ -239,11 +389,41 @@ func (p *Parser) statement() (statement.Statement, *ParseError) {
 	return p.expressionStatement()
 }
 
+// labeled -> identifier ":" ( for | while );
+func (p *Parser) labeledStatement() (statement.Statement, *ParseError) {
+	label := p.next()
+	p.next()
+
+	if p.hasLoop(label) {
+		return nil, newParseError(label, fmt.Sprintf("Label '%s' is already in use.", label.Lexeme))
+	}
+
+	if p.match(tokens.TokenFor) {
+		return p.forStatement(label)
+	}
+
+	if p.match(tokens.TokenWhile) {
+		return p.whileStatement(label)
+	}
+
+	return nil, newParseError(p.peek(), "Expected a loop after label")
+}
+
+// loopBody parses the body of a loop with its label in scope
+func (p *Parser) loopBody(label tokens.Token) (statement.Statement, *ParseError) {
+	p.loops = append(p.loops, label)
+	defer func() { p.loops = p.loops[:len(p.loops)-1] }()
+
+	return p.statement()
+}
+
 // for -> "for" "(" (varDecl | exprStatement | ";") expression?";" expression?";" statement ;
-func (p *Parser) forStatement() (statement.Statement, *ParseError) {
-	p.inLoop = true
-	p.consume(tokens.TokenOparen, "Expected '(' after 'for'")
-	var err *ParseError
+func (p *Parser) forStatement(label tokens.Token) (statement.Statement, *ParseError) {
+	_, err := p.consume(tokens.TokenOparen, "Expected '(' after 'for'")
+
+	if err != nil {
+		return nil, err
+	}
 
 	// first clause in the for loop
 	var initializer statement.Statement
@@ -272,7 +452,9 @@ func (p *Parser) forStatement() (statement.Statement, *ParseError) {
 
 	}
 
-	p.consume(tokens.TokenSemi, "Expected ';' after loop condition")
+	if _, err = p.consume(tokens.TokenSemi, "Expected ';' after loop condition"); err != nil {
+		return nil, err
+	}
 
 	// Third clause in the for loop
 	var increment expression.Expression
@@ -291,22 +473,18 @@ func (p *Parser) forStatement() (statement.Statement, *ParseError) {
 		return nil, err
 	}
 
-	body, err := p.statement()
+	body, err := p.loopBody(label)
 
 	if err != nil {
 		return nil, err
 	}
 
-	// Append the increment to the bottom of the block if set
-	if increment != nil {
-		body.(*statement.BlockStatement).Statements = append(body.(*statement.BlockStatement).Statements, statement.NewExpressionStatement(increment))
-	}
-
 	if condition == nil {
 		condition = expression.NewLiteralExpression(true)
 	}
 
-	body = statement.NewWhileStatement(condition, body)
+	// The increment stays apart from the body so continue still runs it
+	body = statement.NewWhileStatement(label, condition, body, increment)
 
 	if initializer != nil {
 		block := []statement.Statement{
@@ -316,60 +494,165 @@ func (p *Parser) forStatement() (statement.Statement, *ParseError) {
 		body = statement.NewBlockStatement(block)
 	}
 
-	p.inLoop = false
-
 	return body, nil
 }
 
-// break -> "break";
-func (p *Parser) breakStatement() (statement.Statement, *ParseError) {
-	if p.inLoop {
-		b := p.prev()
-		_, err := p.consume(tokens.TokenSemi, "Expected ';' after break statement")
+// break -> "break" identifier? ";"; continue -> "continue" identifier? ";";
+func (p *Parser) loopControl(keyword string) (statement.Statement, *ParseError) {
+	instance := p.prev()
 
-		if err != nil {
+	if len(p.loops) == 0 {
+		return nil, newParseError(instance, fmt.Sprintf("Expected '%s' inside of while or for loop", keyword))
+	}
+
+	var label tokens.Token
+	if p.match(tokens.TokenIdentifier) {
+		label = p.prev()
+
+		if !p.hasLoop(label) {
+			return nil, newParseError(label, fmt.Sprintf("No enclosing loop labeled '%s'.", label.Lexeme))
+		}
+	}
+
+	if _, err := p.consume(tokens.TokenSemi, fmt.Sprintf("Expected ';' after %s statement", keyword)); err != nil {
+		return nil, err
+	}
+
+	if keyword == "continue" {
+		return statement.NewContinueStatement(instance, label), nil
+	}
+
+	return statement.NewBreakStatement(instance, label), nil
+}
+
+// hasLoop reports whether a loop being parsed is labeled label
+func (p *Parser) hasLoop(label tokens.Token) bool {
+	for _, enclosing := range p.loops {
+		if enclosing.Lexeme == label.Lexeme {
+			return true
+		}
+	}
+
+	return false
+}
+
+// throw -> "throw" expression ";";
+func (p *Parser) throwStatement() (statement.Statement, *ParseError) {
+	keyword := p.prev()
+	value, err := p.expression()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = p.consume(tokens.TokenSemi, "Expected ';' after thrown value"); err != nil {
+		return nil, err
+	}
+
+	return statement.NewThrowStatement(keyword, value), nil
+}
+
+// try -> "try" block ( "catch" "(" identifier ")" block )? ( "finally" block )?; at least one of
+// the clauses has to be there
+func (p *Parser) tryStatement() (statement.Statement, *ParseError) {
+	keyword := p.prev()
+
+	if _, err := p.consume(tokens.TokenOsquiggle, "Expected '{' after 'try'"); err != nil {
+		return nil, err
+	}
+
+	body, err := p.block()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var catchName tokens.Token
+	var catch, finally []statement.Statement
+
+	if p.match(tokens.TokenCatch) {
+		if _, err = p.consume(tokens.TokenOparen, "Expected '(' after 'catch'"); err != nil {
+			return nil, err
+		}
+
+		if catchName, err = p.consume(tokens.TokenIdentifier, "Expected error variable name"); err != nil {
+			return nil, err
+		}
+
+		if _, err = p.consume(tokens.TokenCparen, "Expected ')' after error variable name"); err != nil {
+			return nil, err
+		}
+
+		if _, err = p.consume(tokens.TokenOsquiggle, "Expected '{' before catch body"); err != nil {
 			return nil, err
 		}
 
-		return statement.NewBreakStatement(b), nil
+		if catch, err = p.block(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.match(tokens.TokenFinally) {
+		if _, err = p.consume(tokens.TokenOsquiggle, "Expected '{' after 'finally'"); err != nil {
+			return nil, err
+		}
+
+		if finally, err = p.block(); err != nil {
+			return nil, err
+		}
 	}
 
-	return nil, newParseError(p.prev(), "Expected 'break' inside of while or for loop")
+	if catch == nil && finally == nil {
+		return nil, newParseError(p.peek(), "Expected 'catch' or 'finally' after try block")
+	}
+
+	return statement.NewTryStatement(keyword, body, catchName, catch, finally), nil
 }
 
 // while -> "while" "(" expression ")" statement;
-func (p *Parser) whileStatement() (statement.Statement, *ParseError) {
-	p.inLoop = true
-	p.consume(tokens.TokenOparen, "Expected '(' after 'while'")
+func (p *Parser) whileStatement(label tokens.Token) (statement.Statement, *ParseError) {
+	_, err := p.consume(tokens.TokenOparen, "Expected '(' after 'while'")
+
+	if err != nil {
+		return nil, err
+	}
+
 	condition, err := p.expression()
 
 	if err != nil {
 		return nil, err
 	}
 
-	p.consume(tokens.TokenCparen, "Expected ')' after condition")
+	if _, err = p.consume(tokens.TokenCparen, "Expected ')' after condition"); err != nil {
+		return nil, err
+	}
 
-	body, err := p.statement()
+	body, err := p.loopBody(label)
 
 	if err != nil {
 		return nil, err
 	}
 
-	p.inLoop = false
-
-	return statement.NewWhileStatement(condition, body), nil
+	return statement.NewWhileStatement(label, condition, body, nil), nil
 }
 
 // if -> "if" "(" expression ")" statement ("else" statement)?;
 func (p *Parser) ifStatement() (statement.Statement, *ParseError) {
-	p.consume(tokens.TokenOparen, "Expected '(' after 'if'")
+	_, err := p.consume(tokens.TokenOparen, "Expected '(' after 'if'")
+
+	if err != nil {
+		return nil, err
+	}
+
 	condition, err := p.expression()
 
 	if err != nil {
 		return nil, err
 	}
 
-	p.consume(tokens.TokenCparen, "Expected ')' after if condition")
+	if _, err = p.consume(tokens.TokenCparen, "Expected ')' after if condition"); err != nil {
+		return nil, err
+	}
 
 	thenBranch, err := p.statement()
 
@@ -423,18 +706,14 @@ func (p *Parser) printStatement() (statement.Statement, *ParseError) {
 	return printStatement, nil
 }
 
-// block -> "{" declaration* "}";
+// block -> "{" declaration* "}"; broken declarations inside are skipped like at the top level
 func (p *Parser) block() ([]statement.Statement, *ParseError) {
 	statements := make([]statement.Statement, 0)
 
 	for !p.check(tokens.TokenCsquiggle) && !p.end() {
-		statement, err := p.declaration()
-
-		if err != nil {
-			return nil, err
+		if statement := p.recoveringDeclaration(); statement != nil {
+			statements = append(statements, statement)
 		}
-
-		statements = append(statements, statement)
 	}
 
 	_, err := p.consume(tokens.TokenCsquiggle, "Expected '}' after block statement")
@@ -446,7 +725,7 @@ func (p *Parser) block() ([]statement.Statement, *ParseError) {
 	return statements, nil
 }
 
-// assignment -> tokens.TokenIdentifier "=" assignment | equality;
+// assignment -> ( call "." )? tokens.TokenIdentifier "=" assignment | call "[" expression "]" "=" assignment | logical or;
 func (p *Parser) assignment() (expression.Expression, *ParseError) {
 	expr, err := p.or()
 	if err != nil {
@@ -465,6 +744,12 @@ func (p *Parser) assignment() (expression.Expression, *ParseError) {
 		case *expression.VariableExpression:
 			name := expr.(*expression.VariableExpression).Name
 			return expression.NewAssignExpression(name, value), nil
+		case *expression.GetExpression:
+			get := expr.(*expression.GetExpression)
+			return expression.NewSetExpression(get.Object, get.Name, value), nil
+		case *expression.IndexExpression:
+			index := expr.(*expression.IndexExpression)
+			return expression.NewIndexSetExpression(index.Object, index.Bracket, index.Index, value), nil
 		default:
 			return nil, newParseError(equals, "Invalid assignment target")
 		}
@@ -540,15 +825,99 @@ func (p *Parser) equality() (expression.Expression, *ParseError) {
 	return expr, nil
 }
 
-// comparison -> term ( ( ">" | ">=" | "<" | "<=" ) term )*;
+// comparison -> bitOr ( ( ">" | ">=" | "<" | "<=" ) bitOr )*;
 func (p *Parser) comparison() (expression.Expression, *ParseError) {
-	expr, err := p.term()
+	expr, err := p.bitOr()
 
 	if err != nil {
 		return nil, err
 	}
 
 	for p.match(tokens.TokenGreater, tokens.TokenGreaterEqual, tokens.TokenLess, tokens.TokenLessEqual) {
+		operator := p.prev()
+		right, err := p.bitOr()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = expression.NewBinaryExpression(expr, right, operator)
+	}
+
+	return expr, nil
+}
+
+// bitOr -> bitXor ( "|" bitXor )*;
+func (p *Parser) bitOr() (expression.Expression, *ParseError) {
+	expr, err := p.bitXor()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(tokens.TokenPipe) {
+		operator := p.prev()
+		right, err := p.bitXor()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = expression.NewBinaryExpression(expr, right, operator)
+	}
+
+	return expr, nil
+}
+
+// bitXor -> bitAnd ( "^" bitAnd )*;
+func (p *Parser) bitXor() (expression.Expression, *ParseError) {
+	expr, err := p.bitAnd()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(tokens.TokenCaret) {
+		operator := p.prev()
+		right, err := p.bitAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = expression.NewBinaryExpression(expr, right, operator)
+	}
+
+	return expr, nil
+}
+
+// bitAnd -> shift ( "&" shift )*;
+func (p *Parser) bitAnd() (expression.Expression, *ParseError) {
+	expr, err := p.shift()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(tokens.TokenAmpersand) {
+		operator := p.prev()
+		right, err := p.shift()
+		if err != nil {
+			return nil, err
+		}
+
+		expr = expression.NewBinaryExpression(expr, right, operator)
+	}
+
+	return expr, nil
+}
+
+// shift -> term ( ( "<<" | ">>" ) term )*;
+func (p *Parser) shift() (expression.Expression, *ParseError) {
+	expr, err := p.term()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(tokens.TokenLessLess, tokens.TokenGreaterGreater) {
 		operator := p.prev()
 		right, err := p.term()
 		if err != nil {
@@ -570,7 +939,7 @@ func (p *Parser) term() (expression.Expression, *ParseError) {
 
 	for p.match(tokens.TokenMinus, tokens.TokenPlus) {
 		operator := p.prev()
-		right, err := p.unary()
+		right, err := p.factor()
 		if err != nil {
 			return nil, err
 		}
@@ -580,14 +949,14 @@ func (p *Parser) term() (expression.Expression, *ParseError) {
 	return expr, nil
 }
 
-// factor -> ( term ( "/" | "*" ) term )*;
+// factor -> ( term ( "/" | "*" | "%" ) term )*;
 func (p *Parser) factor() (expression.Expression, *ParseError) {
 	expr, err := p.unary()
 	if err != nil {
 		return nil, err
 	}
 
-	for p.match(tokens.TokenSlash, tokens.TokenStar) {
+	for p.match(tokens.TokenSlash, tokens.TokenStar, tokens.TokenModulo) {
 		operator := p.prev()
 		right, err := p.unary()
 
@@ -616,7 +985,7 @@ func (p *Parser) unary() (expression.Expression, *ParseError) {
 	return p.call()
 }
 
-// call -> primary ( "(" arguments? ")" )*;
+// call -> primary ( "(" arguments? ")" | "." identifier | "[" expression "]" )*;
 func (p *Parser) call() (expression.Expression, *ParseError) {
 	expr, err := p.primary()
 
@@ -632,6 +1001,29 @@ func (p *Parser) call() (expression.Expression, *ParseError) {
 				return nil, err
 			}
 
+		} else if p.match(tokens.TokenDot) {
+			name, err := p.consume(tokens.TokenIdentifier, "Expected property name after '.'.")
+
+			if err != nil {
+				return nil, err
+			}
+
+			expr = expression.NewGetExpression(expr, name)
+		} else if p.match(tokens.TokenObracket) {
+			bracket := p.prev()
+			index, err := p.expression()
+
+			if err != nil {
+				return nil, err
+			}
+
+			_, err = p.consume(tokens.TokenCbracket, "Expected ']' after index.")
+
+			if err != nil {
+				return nil, err
+			}
+
+			expr = expression.NewIndexExpression(expr, bracket, index)
 		} else {
 			break
 		}
@@ -640,7 +1032,7 @@ func (p *Parser) call() (expression.Expression, *ParseError) {
 	return expr, nil
 }
 
-// primary -> tokens.TokenNumber | tokens.TokenString | "true" | "false" | "nil" | "(" expression ")";
+// primary -> tokens.TokenNumber | tokens.TokenString | "true" | "false" | "nil" | "this" | "super" "." identifier | "fun" "(" parameters ")" block | "[" elements? "]" | "{" entries? "}" | "(" expression ")" | identifier;
 func (p *Parser) primary() (expression.Expression, *ParseError) {
 	if p.match(tokens.TokenFalse) {
 		return expression.NewLiteralExpression(false), nil
@@ -658,6 +1050,57 @@ func (p *Parser) primary() (expression.Expression, *ParseError) {
 		return expression.NewLiteralExpression(p.prev().Literal), nil
 	}
 
+	if p.match(tokens.TokenInterpolation) {
+		return p.interpolation()
+	}
+
+	if p.match(tokens.TokenThis) {
+		return expression.NewThisExpression(p.prev()), nil
+	}
+
+	if p.match(tokens.TokenFun) {
+		keyword := p.prev()
+		_, err := p.consume(tokens.TokenOparen, "Expected '(' after 'fun'.")
+
+		if err != nil {
+			return nil, err
+		}
+
+		arguments, body, err := p.functionBody("function")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return expression.NewFunctionExpression(keyword, arguments, body), nil
+	}
+
+	if p.match(tokens.TokenObracket) {
+		return p.list()
+	}
+
+	// Statements starting with '{' are blocks, so a brace here is always a map
+	if p.match(tokens.TokenOsquiggle) {
+		return p.mapLiteral()
+	}
+
+	if p.match(tokens.TokenSuper) {
+		keyword := p.prev()
+		_, err := p.consume(tokens.TokenDot, "Expected '.' after 'super'.")
+
+		if err != nil {
+			return nil, err
+		}
+
+		method, err := p.consume(tokens.TokenIdentifier, "Expected superclass method name.")
+
+		if err != nil {
+			return nil, err
+		}
+
+		return expression.NewSuperExpression(keyword, method), nil
+	}
+
 	if p.match(tokens.TokenOparen) {
 		expr, err := p.expression()
 		if err != nil {
@@ -679,6 +1122,108 @@ func (p *Parser) primary() (expression.Expression, *ParseError) {
 	return nil, newParseError(p.peek(), "Expected expression.")
 }
 
+// interpolation -> INTERPOLATION expression ( INTERPOLATION expression )* "}" STRING;
+func (p *Parser) interpolation() (expression.Expression, *ParseError) {
+	start := p.prev()
+	parts := []expression.Expression{expression.NewLiteralExpression(start.Literal)}
+
+	for {
+		part, err := p.expression()
+
+		if err != nil {
+			return nil, err
+		}
+
+		parts = append(parts, part)
+
+		// The tokenizer ends each ${ with the segment that follows it
+		segment := p.peek()
+		if !p.match(tokens.TokenInterpolation) {
+			if segment, err = p.consume(tokens.TokenString, "Expected '}' after interpolated expression."); err != nil {
+				return nil, err
+			}
+		}
+
+		if segment.Literal != "" {
+			parts = append(parts, expression.NewLiteralExpression(segment.Literal))
+		}
+
+		if segment.Variant == tokens.TokenString {
+			return expression.NewInterpolationExpression(start, parts), nil
+		}
+	}
+}
+
+// elements -> expression ( "," expression )* ","? "]";
+func (p *Parser) list() (expression.Expression, *ParseError) {
+	bracket := p.prev()
+	elements := make([]expression.Expression, 0)
+
+	for !p.check(tokens.TokenCbracket) && !p.end() {
+		element, err := p.expression()
+
+		if err != nil {
+			return nil, err
+		}
+
+		elements = append(elements, element)
+
+		if !p.match(tokens.TokenComma) {
+			break
+		}
+	}
+
+	_, err := p.consume(tokens.TokenCbracket, "Expected ']' after list elements.")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return expression.NewListExpression(bracket, elements), nil
+}
+
+// entries -> expression ":" expression ( "," expression ":" expression )* ","? "}";
+func (p *Parser) mapLiteral() (expression.Expression, *ParseError) {
+	brace := p.prev()
+	keys := make([]expression.Expression, 0)
+	values := make([]expression.Expression, 0)
+
+	for !p.check(tokens.TokenCsquiggle) && !p.end() {
+		key, err := p.expression()
+
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.consume(tokens.TokenColon, "Expected ':' after map key.")
+
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := p.expression()
+
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+		values = append(values, value)
+
+		if !p.match(tokens.TokenComma) {
+			break
+		}
+	}
+
+	_, err := p.consume(tokens.TokenCsquiggle, "Expected '}' after map entries.")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return expression.NewMapExpression(brace, keys, values), nil
+}
+
 func (p *Parser) finishCall(callee expression.Expression) (expression.Expression, *ParseError) {
 	arguments := make([]expression.Expression, 0)
 
@@ -716,25 +1261,33 @@ func (p *Parser) consume(t tokens.TokenType, errorMsg string) (tokens.Token, *Pa
 	return tokens.Token{}, newParseError(p.peek(), errorMsg)
 }
 
-// Synchronize when we've caught an error to the next valid tokens.Token
+// synchronize skips the rest of a broken statement. It stops after a ';', before a keyword that
+// starts a statement, or before a '}' so the enclosing block still closes.
 func (p *Parser) synchronize() {
-	p.next()
+	// depth counts the braces opened since the error, their contents belong to the broken
+	// declaration and are skipped whole
+	depth := 0
 
 	for !p.end() {
-		if p.prev().Variant == tokens.TokenSemi {
+		if depth == 0 && p.current > 0 && p.prev().Variant == tokens.TokenSemi {
 			return
 		}
 
 		switch p.peek().Variant {
-		case tokens.TokenClass:
-		case tokens.TokenFun:
-		case tokens.TokenVar:
-		case tokens.TokenFor:
-		case tokens.TokenIf:
-		case tokens.TokenWhile:
-		case tokens.TokenPrint:
-		case tokens.TokenReturn:
-			return
+		case tokens.TokenOsquiggle:
+			depth++
+		case tokens.TokenCsquiggle:
+			if depth == 0 {
+				return
+			}
+
+			depth--
+		case tokens.TokenClass, tokens.TokenFun, tokens.TokenVar, tokens.TokenFor, tokens.TokenIf,
+			tokens.TokenWhile, tokens.TokenPrint, tokens.TokenReturn, tokens.TokenBreak,
+			tokens.TokenContinue, tokens.TokenImport, tokens.TokenThrow, tokens.TokenTry:
+			if depth == 0 {
+				return
+			}
 		}
 
 		p.next()
@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+type recoveryTest struct {
+	Name       string
+	Source     string
+	Statements int
+	Expected   []string
+}
+
+func parse(t *testing.T, source string) (int, []string) {
+	toks, err := tokens.NewTokenizer(source).ScanTokens()
+	if err != nil {
+		t.Fatalf("failed to tokenize test source: %v", err)
+	}
+
+	statements, errs := NewParser(toks).Parse()
+
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	return len(statements), messages
+}
+
+func TestRecovery(t *testing.T) {
+	tests := []recoveryTest{
+		{
+			Name:       "Every statement error is reported",
+			Source:     "print 1 +;\nvar = 2;\nprint 3;\nvar b = (4;\nprint 5;",
+			Statements: 2,
+			Expected: []string{
+				"ParseError: [line 1] Error at ';': Expected expression.",
+				"ParseError: [line 2] Error at '=': Expected variable name.",
+				"ParseError: [line 4] Error at ';': Expected ')' after expression.",
+			},
+		},
+		{
+			Name:       "Missing semicolons after return and var are reported",
+			Source:     "fun f() {\n  return 1\n}\nvar a = 1\nprint a;",
+			Statements: 2,
+			Expected: []string{
+				"ParseError: [line 3] Error at '}': Expected ';' after return value",
+				"ParseError: [line 5] Error at 'print': Expected ';' after variable declaration",
+			},
+		},
+		{
+			Name:       "Errors inside a block leave the block intact",
+			Source:     "{\n  print 1\n  print 2;\n}\nprint 3;",
+			Statements: 2,
+			Expected: []string{
+				"ParseError: [line 3] Error at 'print': Expected a ';' after value.",
+			},
+		},
+		{
+			Name:       "Broken conditions are reported",
+			Source:     "if 1) print 1;\nwhile (true print 2;\nfor x) print 3;\nprint 4;",
+			Statements: 4,
+			Expected: []string{
+				"ParseError: [line 1] Error at '1': Expected '(' after 'if'",
+				"ParseError: [line 2] Error at 'print': Expected ')' after condition",
+				"ParseError: [line 3] Error at 'x': Expected '(' after 'for'",
+			},
+		},
+		{
+			Name:       "A stray brace does not stop parsing",
+			Source:     "}\nprint 1;\nprint 2 +;",
+			Statements: 1,
+			Expected: []string{
+				"ParseError: [line 1] Error at '}': Expected expression.",
+				"ParseError: [line 3] Error at ';': Expected expression.",
+			},
+		},
+		{
+			Name:       "A broken function skips its body",
+			Source:     "fun f( { }\nfun g( { if (true) { print 1; } }\nprint 2;",
+			Statements: 1,
+			Expected: []string{
+				"ParseError: [line 1] Error at '{': Expected argument name.",
+				"ParseError: [line 2] Error at '{': Expected argument name.",
+			},
+		},
+		{
+			Name:       "A broken class skips its body",
+			Source:     "class { }\nprint 1;",
+			Statements: 1,
+			Expected: []string{
+				"ParseError: [line 1] Error at '{': Expected class name.",
+			},
+		},
+		{
+			Name:       "A self inheriting class is left to the resolver",
+			Source:     "class D < D {\n m(a) { print a; }\n}\nprint 1;",
+			Statements: 2,
+			Expected:   []string{},
+		},
+		{
+			Name:       "A broken method leaves the class brace behind",
+			Source:     "class A {\n  m( { }\n  n() { if (true) { print 1; } }\n}\nprint 2 +;",
+			Statements: 0,
+			Expected: []string{
+				"ParseError: [line 2] Error at '{': Expected argument name.",
+				"ParseError: [line 5] Error at ';': Expected expression.",
+			},
+		},
+		{
+			Name:       "A try needs a catch or finally clause",
+			Source:     "try { print 1; }\nprint 2;\ntry { } catch e;\nprint 3;",
+			Statements: 2,
+			Expected: []string{
+				"ParseError: [line 2] Error at 'print': Expected 'catch' or 'finally' after try block",
+				"ParseError: [line 3] Error at 'e': Expected '(' after 'catch'",
+			},
+		},
+		{
+			Name:       "Loop control needs an enclosing loop",
+			Source:     "while (true) { while (true) {} break; }\nbreak;\ncontinue;\nwhile (true) { fun f() { break; } }",
+			Statements: 2,
+			Expected: []string{
+				"ParseError: [line 2] Error at 'break': Expected 'break' inside of while or for loop",
+				"ParseError: [line 3] Error at 'continue': Expected 'continue' inside of while or for loop",
+				"ParseError: [line 4] Error at 'break': Expected 'break' inside of while or for loop",
+			},
+		},
+		{
+			Name:       "Labels must name an enclosing loop",
+			Source:     "a: while (true) { break b; }\na: while (true) { a: for (;;) {} }\nc: print 1;\nd: while (true) continue d;",
+			Statements: 4,
+			Expected: []string{
+				"ParseError: [line 1] Error at 'b': No enclosing loop labeled 'b'.",
+				"ParseError: [line 2] Error at 'a': Label 'a' is already in use.",
+				"ParseError: [line 3] Error at 'print': Expected a loop after label",
+			},
+		},
+		{
+			Name:       "Valid programs have no errors",
+			Source:     "var a = 1;\nprint a;",
+			Statements: 2,
+			Expected:   []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+		statements, errs := parse(t, test.Source)
+
+		if statements != test.Statements {
+			t.Errorf("%s: expected %d statements but got %d", test.Name, test.Statements, statements)
+		}
+
+		if len(errs) != len(test.Expected) {
+			t.Errorf("%s: expected errors %q but got %q", test.Name, test.Expected, errs)
+			continue
+		}
+
+		for n := range errs {
+			if errs[n] != test.Expected[n] {
+				t.Errorf("%s: expected error %q but got %q", test.Name, test.Expected[n], errs[n])
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package resolver
+
+import (
+	"fmt"
+
+	"github.com/jparr721/obsidian/internal/diagnostics"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+// ResolveError represents a semantic error or warning found before the program runs
+type ResolveError struct {
+	token   tokens.Token
+	message string
+	warning bool
+}
+
+func newResolveError(token tokens.Token, message string) *ResolveError {
+	return &ResolveError{token, message, false}
+}
+
+func newResolveWarning(token tokens.Token, message string) *ResolveError {
+	return &ResolveError{token, message, true}
+}
+
+// IsWarning reports whether the program can still run despite this error
+func (r *ResolveError) IsWarning() bool {
+	return r.warning
+}
+
+func (r *ResolveError) Error() string {
+	if r.warning {
+		return fmt.Sprintf("ResolveWarning: [line %d] Warning at '%s': %s", r.token.Line, r.token.Lexeme, r.message)
+	}
+
+	return fmt.Sprintf("ResolveError: [line %d] Error at '%s': %s", r.token.Line, r.token.Lexeme, r.message)
+}
+
+// Diagnostic points at the name or keyword the resolver rejected
+func (r *ResolveError) Diagnostic() *diagnostics.Diagnostic {
+	if r.warning {
+		return &diagnostics.Diagnostic{
+			Severity: diagnostics.SeverityWarning,
+			Code:     diagnostics.CodeResolveWarning,
+			Message:  r.message,
+			Span:     r.token.Span(),
+		}
+	}
+
+	return &diagnostics.Diagnostic{Code: diagnostics.CodeResolve, Message: r.message, Span: r.token.Span()}
+}
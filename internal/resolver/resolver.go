@@ -0,0 +1,494 @@
+package resolver
+
+import (
+	"fmt"
+
+	"github.com/jparr721/obsidian/internal/expression"
+	"github.com/jparr721/obsidian/internal/statement"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+type functionType int
+
+const (
+	functionNone functionType = iota
+	functionFunction
+	functionMethod
+	functionInitializer
+)
+
+type classType int
+
+const (
+	classNone classType = iota
+	classClass
+	classSubclass
+)
+
+// variable tracks a single local through its declaration, definition and uses
+type variable struct {
+	name    tokens.Token
+	defined bool
+	used    bool
+
+	// warnUnused is only set for `var` declarations, parameters and functions are exempt
+	warnUnused bool
+}
+
+// scope is a block's locals, order keeps warnings in declaration order
+type scope struct {
+	variables map[string]*variable
+	order     []*variable
+}
+
+func newScope() *scope {
+	return &scope{make(map[string]*variable), make([]*variable, 0)}
+}
+
+// Resolver walks the AST once before it runs to bind each local reference to its scope depth
+type Resolver struct {
+	scopes          []*scope
+	locals          map[expression.Expression]int
+	currentFunction functionType
+	currentClass    classType
+	errors          []*ResolveError
+	warnings        []*ResolveError
+}
+
+// NewResolver creates a resolver with no scopes, top level names are globals
+func NewResolver() *Resolver {
+	return &Resolver{
+		scopes:          make([]*scope, 0),
+		locals:          make(map[expression.Expression]int),
+		currentFunction: functionNone,
+		currentClass:    classNone,
+		errors:          make([]*ResolveError, 0),
+		warnings:        make([]*ResolveError, 0),
+	}
+}
+
+// Resolve returns how many scopes away each local variable reference was declared, along
+// with every error found. Expressions missing from the result refer to globals.
+func (r *Resolver) Resolve(statements []statement.Statement) (map[expression.Expression]int, []*ResolveError) {
+	r.statements(statements)
+	return r.locals, r.errors
+}
+
+// Warnings returns the problems found that do not stop the program from running
+func (r *Resolver) Warnings() []*ResolveError {
+	return r.warnings
+}
+
+func (r *Resolver) error(token tokens.Token, message string) {
+	r.errors = append(r.errors, newResolveError(token, message))
+}
+
+func (r *Resolver) statements(statements []statement.Statement) {
+	for _, s := range statements {
+		r.statement(s)
+	}
+}
+
+func (r *Resolver) statement(s statement.Statement) {
+	s.Accept(r)
+}
+
+func (r *Resolver) expression(e expression.Expression) {
+	e.Accept(r)
+}
+
+func (r *Resolver) beginScope() {
+	r.scopes = append(r.scopes, newScope())
+}
+
+func (r *Resolver) endScope() {
+	ended := r.scopes[len(r.scopes)-1]
+	r.scopes = r.scopes[:len(r.scopes)-1]
+
+	for _, v := range ended.order {
+		if v.warnUnused && !v.used {
+			r.warnings = append(r.warnings, newResolveWarning(v.name, fmt.Sprintf("Local variable '%s' is never used.", v.name.Lexeme)))
+		}
+	}
+}
+
+func (r *Resolver) declare(name tokens.Token, warnUnused bool) {
+	if len(r.scopes) == 0 {
+		return
+	}
+
+	current := r.scopes[len(r.scopes)-1]
+
+	if _, ok := current.variables[name.Lexeme]; ok {
+		r.error(name, "Already a variable with this name in this scope.")
+		return
+	}
+
+	v := &variable{name: name, warnUnused: warnUnused}
+	current.variables[name.Lexeme] = v
+	current.order = append(current.order, v)
+}
+
+func (r *Resolver) define(name tokens.Token) {
+	if len(r.scopes) == 0 {
+		return
+	}
+
+	if v, ok := r.scopes[len(r.scopes)-1].variables[name.Lexeme]; ok {
+		v.defined = true
+	}
+}
+
+// defineSynthetic adds a name the language creates for you, like this and super
+func (r *Resolver) defineSynthetic(name string) {
+	current := r.scopes[len(r.scopes)-1]
+	current.variables[name] = &variable{name: tokens.NewToken(tokens.TokenIdentifier, name, nil, 0), defined: true}
+}
+
+// resolveLocal records the depth of the innermost scope declaring name
+func (r *Resolver) resolveLocal(e expression.Expression, name tokens.Token, read bool) {
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if v, ok := r.scopes[i].variables[name.Lexeme]; ok {
+			if read {
+				v.used = true
+			}
+
+			r.locals[e] = len(r.scopes) - 1 - i
+			return
+		}
+	}
+}
+
+func (r *Resolver) resolveFunction(arguments []tokens.Token, body []statement.Statement, kind functionType) {
+	enclosing := r.currentFunction
+	r.currentFunction = kind
+
+	r.beginScope()
+	for _, param := range arguments {
+		r.declare(param, false)
+		r.define(param)
+	}
+	r.statements(body)
+	r.endScope()
+
+	r.currentFunction = enclosing
+}
+
+// VisitBlockStatement resolves a block in its own scope
+func (r *Resolver) VisitBlockStatement(s *statement.BlockStatement) (interface{}, error) {
+	r.beginScope()
+	r.statements(s.Statements)
+	r.endScope()
+	return nil, nil
+}
+
+// VisitClassStatement resolves methods inside scopes for super and this
+func (r *Resolver) VisitClassStatement(s *statement.ClassStatement) (interface{}, error) {
+	enclosing := r.currentClass
+	r.currentClass = classClass
+
+	r.declare(s.Name, false)
+	r.define(s.Name)
+
+	if s.Superclass != nil {
+		if s.Superclass.Name.Lexeme == s.Name.Lexeme {
+			r.error(s.Superclass.Name, "A class can't inherit from itself.")
+		}
+
+		r.currentClass = classSubclass
+		r.expression(s.Superclass)
+
+		r.beginScope()
+		r.defineSynthetic("super")
+	}
+
+	r.beginScope()
+	r.defineSynthetic("this")
+
+	for _, method := range s.Methods {
+		kind := functionMethod
+		if method.Name.Lexeme == "init" {
+			kind = functionInitializer
+		}
+
+		r.resolveFunction(method.Arguments, method.Body, kind)
+	}
+
+	r.endScope()
+
+	if s.Superclass != nil {
+		r.endScope()
+	}
+
+	r.currentClass = enclosing
+	return nil, nil
+}
+
+// VisitImportStatement only allows imports at the top level, where module names are globals
+func (r *Resolver) VisitImportStatement(s *statement.ImportStatement) (interface{}, error) {
+	if len(r.scopes) > 0 {
+		r.error(s.Keyword, "Imports must be at the top level of a file.")
+	}
+
+	return nil, nil
+}
+
+// VisitVariableStatement declares the name before its initializer so self reads can be caught
+func (r *Resolver) VisitVariableStatement(s *statement.VariableStatement) (interface{}, error) {
+	r.declare(s.Name, true)
+
+	if s.Initializer != nil {
+		r.expression(s.Initializer)
+	}
+
+	r.define(s.Name)
+	return nil, nil
+}
+
+// VisitFunctionStatement defines the name first so functions can recurse
+func (r *Resolver) VisitFunctionStatement(s *statement.FunctionStatement) (interface{}, error) {
+	r.declare(s.Name, false)
+	r.define(s.Name)
+
+	r.resolveFunction(s.Arguments, s.Body, functionFunction)
+	return nil, nil
+}
+
+// VisitExpressionStatement resolves the wrapped expression
+func (r *Resolver) VisitExpressionStatement(s *statement.ExpressionStatement) (interface{}, error) {
+	r.expression(s.Expression)
+	return nil, nil
+}
+
+// VisitIfStatement resolves both branches
+func (r *Resolver) VisitIfStatement(s *statement.IfStatement) (interface{}, error) {
+	r.expression(s.Condition)
+	r.statement(s.ThenBranch)
+
+	if s.ElseBranch != nil {
+		r.statement(s.ElseBranch)
+	}
+
+	return nil, nil
+}
+
+// VisitPrintStatement resolves the printed expression
+func (r *Resolver) VisitPrintStatement(s *statement.PrintStatement) (interface{}, error) {
+	r.expression(s.Expression)
+	return nil, nil
+}
+
+// VisitReturnStatement rejects returns outside of functions and values returned from init
+func (r *Resolver) VisitReturnStatement(s *statement.ReturnStatement) (interface{}, error) {
+	if r.currentFunction == functionNone {
+		r.error(s.Keyword, "Can't return from top-level code.")
+	}
+
+	if s.Value != nil {
+		if r.currentFunction == functionInitializer {
+			r.error(s.Keyword, "Can't return a value from an initializer.")
+		}
+
+		r.expression(s.Value)
+	}
+
+	return nil, nil
+}
+
+// VisitWhileStatement resolves the condition, body and increment
+func (r *Resolver) VisitWhileStatement(s *statement.WhileStatement) (interface{}, error) {
+	r.expression(s.Condition)
+	r.statement(s.Body)
+
+	if s.Increment != nil {
+		r.expression(s.Increment)
+	}
+
+	return nil, nil
+}
+
+// VisitBreakStatement has nothing to resolve
+func (r *Resolver) VisitBreakStatement(s *statement.BreakStatement) (interface{}, error) {
+	return nil, nil
+}
+
+// VisitContinueStatement has nothing to resolve
+func (r *Resolver) VisitContinueStatement(s *statement.ContinueStatement) (interface{}, error) {
+	return nil, nil
+}
+
+// VisitThrowStatement resolves the thrown value
+func (r *Resolver) VisitThrowStatement(s *statement.ThrowStatement) (interface{}, error) {
+	r.expression(s.Value)
+	return nil, nil
+}
+
+// VisitTryStatement resolves each clause in its own scope, the error variable shares the catch
+// body's scope like parameters share a function's
+func (r *Resolver) VisitTryStatement(s *statement.TryStatement) (interface{}, error) {
+	r.beginScope()
+	r.statements(s.Body)
+	r.endScope()
+
+	if s.Catch != nil {
+		r.beginScope()
+		r.declare(s.CatchName, false)
+		r.define(s.CatchName)
+		r.statements(s.Catch)
+		r.endScope()
+	}
+
+	if s.Finally != nil {
+		r.beginScope()
+		r.statements(s.Finally)
+		r.endScope()
+	}
+
+	return nil, nil
+}
+
+// VisitVariableExpression binds a read and rejects reads inside the variable's own initializer
+func (r *Resolver) VisitVariableExpression(e *expression.VariableExpression) (interface{}, error) {
+	if len(r.scopes) > 0 {
+		if v, ok := r.scopes[len(r.scopes)-1].variables[e.Name.Lexeme]; ok && !v.defined {
+			r.error(e.Name, "Can't read local variable in its own initializer.")
+		}
+	}
+
+	r.resolveLocal(e, e.Name, true)
+	return nil, nil
+}
+
+// VisitAssignExpression binds a write, writes alone don't count as a use
+func (r *Resolver) VisitAssignExpression(e *expression.AssignExpression) (interface{}, error) {
+	r.expression(e.Value)
+	r.resolveLocal(e, e.Name, false)
+	return nil, nil
+}
+
+// VisitBinaryExpression resolves both operands
+func (r *Resolver) VisitBinaryExpression(e *expression.BinaryExpression) (interface{}, error) {
+	r.expression(e.Left)
+	r.expression(e.Right)
+	return nil, nil
+}
+
+// VisitCallExpression resolves the callee and each argument
+func (r *Resolver) VisitCallExpression(e *expression.CallExpression) (interface{}, error) {
+	r.expression(e.Callee)
+
+	for _, argument := range e.Arguments {
+		r.expression(argument)
+	}
+
+	return nil, nil
+}
+
+// VisitGetExpression resolves the object, properties are looked up dynamically
+func (r *Resolver) VisitGetExpression(e *expression.GetExpression) (interface{}, error) {
+	r.expression(e.Object)
+	return nil, nil
+}
+
+// VisitSetExpression resolves the object and the assigned value
+func (r *Resolver) VisitSetExpression(e *expression.SetExpression) (interface{}, error) {
+	r.expression(e.Value)
+	r.expression(e.Object)
+	return nil, nil
+}
+
+// VisitThisExpression binds this to the enclosing method's scope
+func (r *Resolver) VisitThisExpression(e *expression.ThisExpression) (interface{}, error) {
+	if r.currentClass == classNone {
+		r.error(e.Keyword, "Can't use 'this' outside of a class.")
+		return nil, nil
+	}
+
+	r.resolveLocal(e, e.Keyword, true)
+	return nil, nil
+}
+
+// VisitSuperExpression binds super to the scope wrapping a subclass's methods
+func (r *Resolver) VisitSuperExpression(e *expression.SuperExpression) (interface{}, error) {
+	if r.currentClass == classNone {
+		r.error(e.Keyword, "Can't use 'super' outside of a class.")
+	} else if r.currentClass != classSubclass {
+		r.error(e.Keyword, "Can't use 'super' in a class with no superclass.")
+	}
+
+	r.resolveLocal(e, e.Keyword, true)
+	return nil, nil
+}
+
+// VisitFunctionExpression resolves an anonymous function like a declared one
+func (r *Resolver) VisitFunctionExpression(e *expression.FunctionExpression) (interface{}, error) {
+	r.resolveFunction(e.Arguments, e.Body.([]statement.Statement), functionFunction)
+	return nil, nil
+}
+
+// VisitInterpolationExpression resolves each interpolated expression
+func (r *Resolver) VisitInterpolationExpression(e *expression.InterpolationExpression) (interface{}, error) {
+	for _, part := range e.Parts {
+		r.expression(part)
+	}
+
+	return nil, nil
+}
+
+// VisitListExpression resolves each element
+func (r *Resolver) VisitListExpression(e *expression.ListExpression) (interface{}, error) {
+	for _, element := range e.Elements {
+		r.expression(element)
+	}
+
+	return nil, nil
+}
+
+// VisitIndexExpression resolves the object and index
+func (r *Resolver) VisitIndexExpression(e *expression.IndexExpression) (interface{}, error) {
+	r.expression(e.Object)
+	r.expression(e.Index)
+	return nil, nil
+}
+
+// VisitIndexSetExpression resolves the object, index and assigned value
+func (r *Resolver) VisitIndexSetExpression(e *expression.IndexSetExpression) (interface{}, error) {
+	r.expression(e.Value)
+	r.expression(e.Object)
+	r.expression(e.Index)
+	return nil, nil
+}
+
+// VisitMapExpression resolves each key and value
+func (r *Resolver) VisitMapExpression(e *expression.MapExpression) (interface{}, error) {
+	for n := range e.Keys {
+		r.expression(e.Keys[n])
+		r.expression(e.Values[n])
+	}
+
+	return nil, nil
+}
+
+// VisitGroupingExpression resolves the inner expression
+func (r *Resolver) VisitGroupingExpression(e *expression.GroupingExpression) (interface{}, error) {
+	r.expression(e.Expression.(expression.Expression))
+	return nil, nil
+}
+
+// VisitLiteralExpression has nothing to resolve
+func (r *Resolver) VisitLiteralExpression(e *expression.LiteralExpression) (interface{}, error) {
+	return nil, nil
+}
+
+// VisitLogicalExpression resolves both operands
+func (r *Resolver) VisitLogicalExpression(e *expression.LogicalExpression) (interface{}, error) {
+	r.expression(e.Left)
+	r.expression(e.Right)
+	return nil, nil
+}
+
+// VisitUnaryExpression resolves the operand
+func (r *Resolver) VisitUnaryExpression(e *expression.UnaryExpression) (interface{}, error) {
+	r.expression(e.Right.(expression.Expression))
+	return nil, nil
+}
@@ -0,0 +1,126 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/jparr721/obsidian/internal/parser"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+type resolveTest struct {
+	Name     string
+	Source   string
+	Expected []string
+}
+
+func resolve(t *testing.T, src string) *Resolver {
+	toks, tokErr := tokens.NewTokenizer(src).ScanTokens()
+	if tokErr != nil {
+		t.Fatalf("failed to tokenize test source: %v", tokErr)
+	}
+
+	statements, parseErrs := parser.NewParser(toks).Parse()
+	if len(parseErrs) > 0 {
+		t.Fatalf("failed to parse test source: %v", parseErrs)
+	}
+
+	r := NewResolver()
+	r.Resolve(statements)
+	return r
+}
+
+func compare(t *testing.T, name string, got []*ResolveError, expected []string) {
+	if len(got) != len(expected) {
+		t.Errorf("%s: got %d errors %v, expected %d", name, len(got), got, len(expected))
+		return
+	}
+
+	for i, err := range got {
+		if err.Error() != expected[i] {
+			t.Errorf("%s: error '%v' did not match expected error '%v'", name, err, expected[i])
+		}
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []resolveTest{
+		{
+			Name:     "Reading a local in its own initializer",
+			Source:   "{ var a = a; print a; }",
+			Expected: []string{"ResolveError: [line 1] Error at 'a': Can't read local variable in its own initializer."},
+		},
+		{
+			Name:     "Globals may refer to themselves",
+			Source:   "var a = 1; var a = a;",
+			Expected: []string{},
+		},
+		{
+			Name:     "Returning from top level code",
+			Source:   "return 1;",
+			Expected: []string{"ResolveError: [line 1] Error at 'return': Can't return from top-level code."},
+		},
+		{
+			Name:     "Redeclaring a local in the same scope",
+			Source:   "fun f(a) { var a = 1; return a; }",
+			Expected: []string{"ResolveError: [line 1] Error at 'a': Already a variable with this name in this scope."},
+		},
+		{
+			Name:     "Shadowing in a nested scope is allowed",
+			Source:   "{ var a = 1; { var a = 2; print a; } print a; }",
+			Expected: []string{},
+		},
+		{
+			Name:     "Returning a value from an initializer",
+			Source:   "class A { init() { return 1; } }",
+			Expected: []string{"ResolveError: [line 1] Error at 'return': Can't return a value from an initializer."},
+		},
+		{
+			Name:   "This and super outside of a subclass",
+			Source: "print this; class A { f() { return super.f(); } }",
+			Expected: []string{
+				"ResolveError: [line 1] Error at 'this': Can't use 'this' outside of a class.",
+				"ResolveError: [line 1] Error at 'super': Can't use 'super' in a class with no superclass.",
+			},
+		},
+		{
+			Name:     "A class inheriting from itself",
+			Source:   "class D < D {\n m(a) { print a; }\n}",
+			Expected: []string{"ResolveError: [line 1] Error at 'D': A class can't inherit from itself."},
+		},
+		{
+			Name:     "Importing inside a function",
+			Source:   "fun f() { import \"a.ob\" as a; }",
+			Expected: []string{"ResolveError: [line 1] Error at 'import': Imports must be at the top level of a file."},
+		},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+		compare(t, test.Name, resolve(t, test.Source).errors, test.Expected)
+	}
+}
+
+func TestUnusedLocalWarnings(t *testing.T) {
+	tests := []resolveTest{
+		{
+			Name:     "Unused local is reported",
+			Source:   "fun f() {\n  var unused = 1;\n  var used = 2;\n  return used;\n}",
+			Expected: []string{"ResolveWarning: [line 2] Warning at 'unused': Local variable 'unused' is never used."},
+		},
+		{
+			Name:     "Assignment alone is not a use",
+			Source:   "{ var a; a = 1; }",
+			Expected: []string{"ResolveWarning: [line 1] Warning at 'a': Local variable 'a' is never used."},
+		},
+		{
+			Name:     "Globals and parameters are never reported",
+			Source:   "var a = 1; fun f(b) {}",
+			Expected: []string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+		compare(t, test.Name, resolve(t, test.Source).Warnings(), test.Expected)
+	}
+}
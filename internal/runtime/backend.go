@@ -0,0 +1,34 @@
+package runtime
+
+import "fmt"
+
+// Backend selects how a program is executed once it has been resolved
+type Backend int
+
+const (
+	// BackendTree walks the syntax tree directly
+	BackendTree Backend = iota
+
+	// BackendVM compiles the syntax tree to bytecode and runs it on the stack machine
+	BackendVM
+)
+
+// ParseBackend maps a backend name from the command line to a Backend
+func ParseBackend(name string) (Backend, error) {
+	switch name {
+	case "tree":
+		return BackendTree, nil
+	case "vm":
+		return BackendVM, nil
+	}
+
+	return BackendTree, fmt.Errorf("unknown backend '%s', expected vm or tree", name)
+}
+
+func (b Backend) String() string {
+	if b == BackendVM {
+		return "vm"
+	}
+
+	return "tree"
+}
@@ -1,67 +1,234 @@
 package runtime
 
-// type repl struct {
-// 	history  []string
-// 	commands []string
-// }
-
-// // recompile rebuilds the history into a "file" with explicit delimiting
-// // when a user runs a REPL session their variables, classes, etc are
-// // always declared.
-// func (r *repl) recompile(file []string) string {
-// 	return strings.Join(r.commands, "")
-// }
-
-// func (r *repl) checkKeyword(text string) bool {
-// 	switch text {
-// 	case ".exit\n":
-// 		os.Exit(0)
-// 	case ".history\n":
-// 		if len(r.commands) == 0 {
-// 			fmt.Println("no commands.")
-// 			return true
-// 		}
-// 		fmt.Println(strings.Join(r.commands, ""))
-// 		return true
-// 	case ".commands\n":
-// 		if len(r.commands) == 0 {
-// 			fmt.Println("no commands.")
-// 			return true
-// 		}
-// 		fmt.Println(strings.Join(r.commands, ""))
-// 		return true
-// 	}
-// 	return false
-// }
-
-// func (r *repl) start() {
-// 	reader := bufio.NewReader(os.Stdin)
-
-// 	fmt.Println("Welcome to obsidian, type '.exit' to exit")
-// 	for {
-// 		fmt.Print("> ")
-// 		text, _ := reader.ReadString('\n')
-
-// 		if r.checkKeyword(text) {
-// 			continue
-// 		}
-
-// 		r.history = append(r.history, text)
-
-// 		// If the statement fails to parse, do not attempt to interpret at all
-// 		_, err := parseStatements(tokenize(text))
-// 		if err != nil {
-// 			fmt.Println(err)
-// 			continue
-// 		}
-
-// 		// If we aren't invoking a print, don't bother running since we verify above
-// 		if !strings.HasPrefix(text, "print") {
-// 			r.commands = append(r.commands, text)
-// 		} else {
-// 			contents := append(r.commands, text)
-// 			file := r.recompile(contents)
-// 			replFileRunner(file)
-// 		}
-// 	}
-// }
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jparr721/obsidian/internal/diagnostics"
+	"github.com/jparr721/obsidian/internal/interpreter"
+	"github.com/jparr721/obsidian/internal/statement"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+const (
+	replPrompt         = "> "
+	replContinuePrompt = "... "
+)
+
+// repl keeps a single interpreter alive between inputs so declarations persist
+type repl struct {
+	interpreter *interpreter.Interpreter
+	history     []string
+	out         io.Writer
+	exited      bool
+
+	// sources holds every input so an error in a function declared earlier can still quote it
+	sources diagnostics.Sources
+}
+
+func newRepl(out io.Writer) *repl {
+	r := &repl{history: make([]string, 0), out: out, sources: diagnostics.Sources{}}
+	r.reset()
+	return r
+}
+
+func (r *repl) reset() {
+	r.interpreter = interpreter.NewInterpreter()
+	r.interpreter.SetOutput(r.out)
+}
+
+// checkKeyword runs a dot command, returning false when the input is not one
+func (r *repl) checkKeyword(text string) bool {
+	fields := strings.Fields(text)
+
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], ".") {
+		return false
+	}
+
+	switch fields[0] {
+	case ".exit":
+		r.exited = true
+	case ".history":
+		if len(r.history) == 0 {
+			fmt.Fprintln(r.out, "no commands.")
+			break
+		}
+
+		for n, entry := range r.history {
+			fmt.Fprintf(r.out, "%3d  %s\n", n+1, entry)
+		}
+	case ".load":
+		if len(fields) != 2 {
+			fmt.Fprintln(r.out, "usage: .load <file>")
+			break
+		}
+
+		rt := NewObcRT()
+		src := rt.readFileContent(fields[1])
+
+		if rt.didError {
+			rt.ReportErrors(r.out)
+			break
+		}
+
+		r.load(fields[1], src)
+	case ".reset":
+		r.reset()
+		fmt.Fprintln(r.out, "environment reset.")
+	default:
+		fmt.Fprintf(r.out, "unknown command '%s', expected .exit, .history, .load <file> or .reset\n", fields[0])
+	}
+
+	return true
+}
+
+// eval runs typed input against the persistent interpreter and echoes bare expression values,
+// name is where errors say the input came from
+func (r *repl) eval(name, src string) {
+	statements, ok := r.prepare(name, src, true)
+
+	if !ok {
+		return
+	}
+
+	for _, s := range statements {
+		if expr, ok := s.(*statement.ExpressionStatement); ok {
+			value, err := r.interpreter.Evaluate(expr.Expression)
+
+			if err != nil {
+				r.sources.Report(r.out, err)
+				return
+			}
+
+			if value != nil {
+				fmt.Fprintln(r.out, interpreter.Stringify(value))
+			}
+			continue
+		}
+
+		if err := r.interpreter.Interpret([]statement.Statement{s}); err != nil {
+			r.sources.Report(r.out, err)
+			return
+		}
+	}
+}
+
+// load runs a file against the persistent interpreter the way obc run does, its expression
+// statements are not echoed
+func (r *repl) load(file, src string) {
+	statements, ok := r.prepare(file, src, false)
+
+	if !ok {
+		return
+	}
+
+	// Imports in the file are found relative to it
+	r.interpreter.SetFile(file)
+	defer r.interpreter.SetFile("")
+
+	if err := r.interpreter.Interpret(statements); err != nil {
+		r.sources.Report(r.out, err)
+	}
+}
+
+// prepare parses and resolves src, reporting any problems. Typed input may leave the ';' off of
+// a bare expression like `1 + 2`.
+func (r *repl) prepare(name, src string, typed bool) ([]statement.Statement, bool) {
+	rt := NewObcRT()
+	rt.sources = r.sources
+	statements := rt.parse(rt.tokenize(name, src))
+
+	if typed && rt.didError && !strings.HasSuffix(strings.TrimSpace(src), ";") && !strings.HasSuffix(strings.TrimSpace(src), "}") {
+		retry := NewObcRT()
+		retry.sources = r.sources
+		retried := retry.parse(retry.tokenize(name, src+";"))
+
+		if !retry.didError {
+			rt, statements = retry, retried
+		}
+	}
+
+	if rt.didError {
+		rt.ReportErrors(r.out)
+		return nil, false
+	}
+
+	locals := rt.resolve(statements)
+	rt.ReportErrors(r.out)
+
+	if rt.didError {
+		return nil, false
+	}
+
+	r.interpreter.Resolve(locals)
+	return statements, true
+}
+
+// start reads inputs until EOF or .exit, buffering lines while the input is incomplete
+func (r *repl) start(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	pending := make([]string, 0)
+
+	fmt.Fprintln(r.out, "Welcome to obsidian, type '.exit' to exit")
+	fmt.Fprint(r.out, replPrompt)
+
+	for !r.exited && scanner.Scan() {
+		line := scanner.Text()
+
+		// A blank line gives up on a pending input, .exit leaves even in the middle of one
+		if len(pending) > 0 && strings.TrimSpace(line) == "" {
+			pending = pending[:0]
+			fmt.Fprint(r.out, replPrompt)
+			continue
+		}
+
+		if (len(pending) == 0 || strings.TrimSpace(line) == ".exit") && r.checkKeyword(line) {
+			if !r.exited {
+				fmt.Fprint(r.out, replPrompt)
+			}
+			continue
+		}
+
+		pending = append(pending, line)
+		src := strings.Join(pending, "\n")
+
+		if incomplete(src) {
+			fmt.Fprint(r.out, replContinuePrompt)
+			continue
+		}
+
+		pending = pending[:0]
+
+		if strings.TrimSpace(src) != "" {
+			r.history = append(r.history, src)
+			r.eval(fmt.Sprintf("<input %d>", len(r.history)), src)
+		}
+
+		fmt.Fprint(r.out, replPrompt)
+	}
+}
+
+// incomplete reports whether src ends inside a string or with braces, parens or brackets left
+// open, in which case the repl reads another line. It scans with the tokenizer so strings, raw
+// strings, interpolations and comments count the way they do when the input runs.
+func incomplete(src string) bool {
+	toks, err := tokens.NewTokenizer(src).ScanTokens()
+
+	if err != nil {
+		return strings.HasPrefix(err.Diagnostic().Message, "Unterminated")
+	}
+
+	depth := 0
+	for _, token := range toks {
+		switch token.Variant {
+		case tokens.TokenOsquiggle, tokens.TokenOparen, tokens.TokenObracket:
+			depth++
+		case tokens.TokenCsquiggle, tokens.TokenCparen, tokens.TokenCbracket:
+			depth--
+		}
+	}
+
+	return depth > 0
+}
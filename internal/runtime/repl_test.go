@@ -0,0 +1,142 @@
+package runtime
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type replTest struct {
+	Name     string
+	Input    string
+	Expected string
+}
+
+func runRepl(input string) string {
+	out := &bytes.Buffer{}
+	newRepl(out).start(strings.NewReader(input))
+
+	// Prompts lead the line the output of the next input starts on
+	lines := strings.Split(out.String(), "\n")
+	for n, line := range lines {
+		for strings.HasPrefix(line, replPrompt) || strings.HasPrefix(line, replContinuePrompt) {
+			line = strings.TrimPrefix(strings.TrimPrefix(line, replPrompt), replContinuePrompt)
+		}
+
+		lines[n] = line
+	}
+
+	return strings.TrimPrefix(strings.Join(lines, "\n"), "Welcome to obsidian, type '.exit' to exit\n")
+}
+
+func TestRepl(t *testing.T) {
+	tests := []replTest{
+		{
+			Name:     "Keeps declarations between inputs",
+			Input:    "var a = 2;\nprint a * 3;\n",
+			Expected: "6\n",
+		},
+		{
+			Name:     "Echoes bare expressions",
+			Input:    "var a = 2;\na + 1\n",
+			Expected: "3\n",
+		},
+		{
+			Name:     "Buffers input while braces are open",
+			Input:    "fun double(n) {\n  return n * 2;\n}\ndouble(21);\n",
+			Expected: "42\n",
+		},
+		{
+			Name:     "Braces inside raw strings are not counted",
+			Input:    "print `{`;\nprint 1;\n",
+			Expected: "{\n1\n",
+		},
+		{
+			Name:     "Braces inside interpolated strings are not counted",
+			Input:    "var m = {\"a\": 1};\nprint \"${m[\"a\"]} {\";\n",
+			Expected: "1 {\n",
+		},
+		{
+			Name:     "Buffers input while a string is open",
+			Input:    "print \"a\nb\";\n",
+			Expected: "a\nb\n",
+		},
+		{
+			Name:     "A blank line cancels a pending input",
+			Input:    "fun f() {\n\nprint 2;\n",
+			Expected: "2\n",
+		},
+		{
+			Name:     "Exit leaves during a pending input",
+			Input:    "fun f() {\n.exit\nprint 1;\n",
+			Expected: "",
+		},
+		{
+			Name:     "Reset clears the environment",
+			Input:    "var a = 1;\n.reset\nprint a;\n",
+			Expected: "environment reset.\nerror[E0005]: Undefined variable 'a'\n --> <input 2>:1:7\n  |\n1 | print a;\n  |       ^\n",
+		},
+		{
+			Name:     "Runtime errors quote the input they happened in",
+			Input:    "fun f() {\n  return -\"x\";\n}\nf();\n",
+			Expected: "Traceback (most recent call last):\n  <input 2>:1:3 in <script>\n    f();\n  <input 1>:2:10 in f\n    return -\"x\";\nerror[E0005]: Operans must be numbers.\n --> <input 1>:2:10\n  |\n2 |   return -\"x\";\n  |          ^\n",
+		},
+		{
+			Name:     "History lists previous inputs",
+			Input:    "var a = 1;\nprint a;\n.history\n",
+			Expected: "1\n  1  var a = 1;\n  2  print a;\n",
+		},
+		{
+			Name:     "Exit stops reading input",
+			Input:    ".exit\nprint 1;\n",
+			Expected: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+
+		if output := runRepl(test.Input); output != test.Expected {
+			t.Errorf("repl output: %q did not match expected output: %q", output, test.Expected)
+		}
+	}
+}
+
+func TestLoadImportsRelativeToTheFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "lib")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"main.ob": "import \"dep.ob\" as dep;\nprint dep.answer;\n",
+		"dep.ob":  "var answer = 42;\n",
+	}
+
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := "42\n"
+	if output := runRepl(".load " + filepath.Join(dir, "main.ob") + "\n"); output != expected {
+		t.Errorf("repl output: %q did not match expected output: %q", output, expected)
+	}
+}
+
+func TestLoadDoesNotEcho(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "quiet.ob")
+	if err := ioutil.WriteFile(file, []byte("var z;\nz = 2;\npush([], 1);\nprint z;\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Typed input still echoes its value afterwards
+	expected := "2\n3\n"
+	if output := runRepl(".load " + file + "\nz + 1\n"); output != expected {
+		t.Errorf("repl output: %q did not match expected output: %q", output, expected)
+	}
+}
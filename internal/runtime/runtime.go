@@ -2,16 +2,24 @@ package runtime
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/jparr721/obsidian/internal/bytecode"
+	"github.com/jparr721/obsidian/internal/compiler"
+	"github.com/jparr721/obsidian/internal/diagnostics"
+	"github.com/jparr721/obsidian/internal/expression"
 	"github.com/jparr721/obsidian/internal/interpreter"
+	"github.com/jparr721/obsidian/internal/module"
 	"github.com/jparr721/obsidian/internal/parser"
+	"github.com/jparr721/obsidian/internal/resolver"
 	"github.com/jparr721/obsidian/internal/statement"
 	"github.com/jparr721/obsidian/internal/tokens"
+	"github.com/jparr721/obsidian/internal/vm"
 )
 
 // ObcRT is the runtime entrypoint struct used to kick off the interpreter. It tracks errors during
@@ -20,6 +28,107 @@ import (
 type ObcRT struct {
 	didError   bool
 	errorStack []error
+
+	// warnings are reported alongside errors but never stop the program
+	warnings []error
+
+	backend Backend
+
+	// out is where the running program prints to
+	out io.Writer
+
+	// searchPaths are where imports are looked for when they are not next to the importing file
+	searchPaths []string
+
+	// sources holds the text of every file tokenized so errors can quote it
+	sources diagnostics.Sources
+}
+
+// NewObcRT creates a runtime with an empty error stack that runs programs on the tree walker.
+// Imports are searched for in the directories listed in OBSIDIAN_PATH.
+func NewObcRT() *ObcRT {
+	return &ObcRT{false, make([]error, 0), make([]error, 0), BackendTree, os.Stdout, searchPathsFromEnv(), diagnostics.Sources{}}
+}
+
+func searchPathsFromEnv() []string {
+	paths := make([]string, 0)
+
+	for _, path := range filepath.SplitList(os.Getenv("OBSIDIAN_PATH")) {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// SetBackend selects the backend Run executes programs with
+func (o *ObcRT) SetBackend(backend Backend) {
+	o.backend = backend
+}
+
+// AddSearchPath adds a directory imports are looked for in, ahead of OBSIDIAN_PATH
+func (o *ObcRT) AddSearchPath(dir string) {
+	o.searchPaths = append([]string{dir}, o.searchPaths...)
+}
+
+// SetOutput redirects the running program's print statements to w
+func (o *ObcRT) SetOutput(w io.Writer) {
+	o.out = w
+}
+
+// DidError reports whether any stage of the pipeline has failed
+func (o *ObcRT) DidError() bool {
+	return o.didError
+}
+
+// Errors returns every error collected so far, oldest first
+func (o *ObcRT) Errors() []error {
+	return o.errorStack
+}
+
+// Warnings returns every warning collected so far, oldest first
+func (o *ObcRT) Warnings() []error {
+	return o.warnings
+}
+
+// ReportWarnings writes every collected warning to w, quoting the source it points at
+func (o *ObcRT) ReportWarnings(w io.Writer) {
+	for _, warning := range o.warnings {
+		o.sources.Report(w, warning)
+	}
+}
+
+// ReportErrors writes every collected warning and error to w, errors that know where they
+// happened quote the offending source
+func (o *ObcRT) ReportErrors(w io.Writer) {
+	o.ReportWarnings(w)
+
+	for _, err := range o.errorStack {
+		o.sources.Report(w, err)
+	}
+}
+
+func (o *ObcRT) pushError(err error) {
+	// A module that failed to parse brings every error found in it
+	if errs, ok := err.(module.Errors); ok {
+		for _, err := range errs {
+			o.pushError(err)
+		}
+
+		return
+	}
+
+	o.didError = true
+	o.errorStack = append(o.errorStack, err)
+}
+
+// loader finds imports on the search paths and records the source of each module so errors
+// inside it can quote it
+func (o *ObcRT) loader() *module.Loader {
+	loader := module.NewLoader(o.searchPaths...)
+	loader.SetSources(o.sources)
+	return loader
 }
 
 func (o *ObcRT) coreDump(metadata interface{}) {
@@ -36,70 +145,285 @@ func (o *ObcRT) coreDump(metadata interface{}) {
 	}
 }
 
-func (o *ObcRT) tokenize(fileContents string) []tokens.Token {
-	tokens, err := tokens.NewTokenizer(fileContents).ScanTokens()
+// tokenize scans the source of filename, which is empty for input that is not from a file
+func (o *ObcRT) tokenize(filename, src string) []tokens.Token {
+	o.sources[filename] = src
+
+	tokenizer := tokens.NewTokenizer(src)
+	tokenizer.SetFile(filename)
+	tokens, err := tokenizer.ScanTokens()
 
 	if err != nil {
-		o.didError = true
-		o.errorStack = append(o.errorStack, err)
+		o.pushError(err)
 	}
 
 	return tokens
 }
 
 func (o *ObcRT) parse(tokens []tokens.Token) []statement.Statement {
-	parsed, err := parser.NewParser(tokens).Parse()
+	parsed, errs := parser.NewParser(tokens).Parse()
 
-	if err != nil {
-		o.didError = true
-		o.errorStack = append(o.errorStack, err)
+	for _, err := range errs {
+		o.pushError(err)
 	}
 
 	return parsed
 }
 
-func (o *ObcRT) interpret(statements []statement.Statement) {
-	err := interpreter.NewInterpreter().Interpret(statements)
+func (o *ObcRT) resolve(statements []statement.Statement) map[expression.Expression]int {
+	r := resolver.NewResolver()
+	locals, errs := r.Resolve(statements)
+
+	for _, warning := range r.Warnings() {
+		o.warnings = append(o.warnings, warning)
+	}
+
+	for _, err := range errs {
+		o.pushError(err)
+	}
+
+	return locals
+}
+
+func (o *ObcRT) interpret(filename string, statements []statement.Statement, locals map[expression.Expression]int) {
+	if o.backend == BackendVM {
+		o.execute(filename, statements)
+		return
+	}
+
+	i := interpreter.NewInterpreter()
+	i.SetOutput(o.out)
+	i.SetLoader(o.loader())
+	i.SetFile(filename)
+	i.Resolve(locals)
+	err := i.Interpret(statements)
 
 	if err != nil {
-		o.didError = true
-		o.errorStack = append(o.errorStack, err)
+		o.pushError(err)
+	}
+}
+
+func (o *ObcRT) compile(statements []statement.Statement) *bytecode.Function {
+	script, errs := compiler.NewCompiler().Compile(statements)
+
+	for _, err := range errs {
+		o.pushError(err)
+	}
+
+	return script
+}
+
+// execute compiles resolved statements to bytecode and runs them on the virtual machine
+func (o *ObcRT) execute(filename string, statements []statement.Statement) {
+	script := o.compile(statements)
+
+	if o.didError {
+		return
+	}
+
+	o.runScript(filename, script)
+}
+
+func (o *ObcRT) runScript(filename string, script *bytecode.Function) {
+	machine := vm.NewVM()
+	machine.SetOutput(o.out)
+	machine.SetLoader(o.loader())
+	machine.SetFile(filename)
+
+	if err := machine.Run(script); err != nil {
+		o.pushError(err)
+	}
+}
+
+// isObject reports whether filename names a compiled object file
+func isObject(filename string) bool {
+	return strings.HasSuffix(filename, ".obc")
+}
+
+// ObjectPath is the default object file name for a source file
+func ObjectPath(filename string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(filename, ".obsidian"), ".ob") + ".obc"
+}
+
+func (o *ObcRT) load(filename string) *bytecode.Function {
+	file, err := os.Open(filename)
+
+	if err != nil {
+		o.pushError(err)
+		return nil
+	}
+
+	defer file.Close()
+	script, err := bytecode.Decode(file)
+
+	if err != nil {
+		o.pushError(fmt.Errorf("%s: %v", filename, err))
+		return nil
+	}
+
+	return script
+}
+
+// Script compiles a source file to bytecode, object files are loaded without reparsing
+func (o *ObcRT) Script(filename string) *bytecode.Function {
+	if isObject(filename) {
+		return o.load(filename)
+	}
+
+	statements := o.Statements(filename)
+
+	if o.didError {
+		return nil
+	}
+
+	o.resolve(statements)
+
+	if o.didError {
+		return nil
+	}
+
+	return o.compile(statements)
+}
+
+// Build compiles a source file and writes it to output as an object file
+func (o *ObcRT) Build(filename, output string) {
+	script := o.Script(filename)
+
+	if o.didError {
+		return
+	}
+
+	file, err := os.Create(output)
+
+	if err != nil {
+		o.pushError(err)
+		return
+	}
+
+	if err := bytecode.Encode(file, script); err != nil {
+		o.pushError(err)
+	}
+
+	if err := file.Close(); err != nil {
+		o.pushError(err)
+	}
+}
+
+// Disassemble writes the bytecode listing of a source or object file to w
+func (o *ObcRT) Disassemble(filename string, w io.Writer) {
+	script := o.Script(filename)
+
+	if !o.didError {
+		bytecode.Disassemble(w, script)
 	}
 }
 
 func (o *ObcRT) readFileContent(filename string) string {
 	if !strings.HasSuffix(filename, ".ob") && !strings.HasSuffix(filename, ".obsidian") {
-		log.Fatal("file must end in '.ob' or '.obsidian'")
+		o.pushError(fmt.Errorf("%s: file must end in '.ob' or '.obsidian'", filename))
+		return ""
 	}
 
 	file, err := ioutil.ReadFile(filename)
 
 	if err != nil {
-		log.Fatal(err)
+		o.pushError(err)
+		return ""
 	}
 
 	return string(file)
 }
 
-func (o *ObcRT) _start(filename string) string {
-	return o.readFileContent(filename)
+// Tokens reads a file and returns its tokens, stopping if the file cannot be read
+func (o *ObcRT) Tokens(filename string) []tokens.Token {
+	src := o.readFileContent(filename)
+
+	if o.didError {
+		return nil
+	}
+
+	return o.tokenize(filename, src)
+}
+
+// Statements reads, tokenizes and parses a file without running it
+func (o *ObcRT) Statements(filename string) []statement.Statement {
+	tokens := o.Tokens(filename)
+
+	if o.didError {
+		return nil
+	}
+
+	return o.parse(tokens)
+}
+
+// Check tokenizes, parses and resolves a file, collecting any errors on the error stack
+func (o *ObcRT) Check(filename string) {
+	statements := o.Statements(filename)
+
+	if o.didError {
+		return
+	}
+
+	o.resolve(statements)
+}
+
+// Run executes a file from start to finish, stopping at the first failing stage. Object files
+// always run on the virtual machine.
+func (o *ObcRT) Run(filename string) {
+	if isObject(filename) {
+		script := o.load(filename)
+
+		if !o.didError {
+			o.runScript(filename, script)
+		}
+
+		return
+	}
+
+	statements := o.Statements(filename)
+
+	if o.didError {
+		return
+	}
+
+	locals := o.resolve(statements)
+
+	if o.didError {
+		return
+	}
+
+	o.interpret(filename, statements, locals)
+}
+
+// Repl starts an interactive session reading from in and writing to out
+func (o *ObcRT) Repl(in io.Reader, out io.Writer) {
+	newRepl(out).start(in)
 }
 
 // Start starts the runtime object and builds the necessary pieces
 func (o *ObcRT) Start(file string, repl bool) {
 	if !repl {
-		file = o._start(file)
+		o.Run(file)
+		return
 	}
 
-	tokens := o.tokenize(file)
+	tokens := o.tokenize("", file)
 
 	if o.didError {
-		for _, err := range o.errorStack {
-			fmt.Println(err)
-		}
+		return
 	}
 
 	statements := o.parse(tokens)
 
-	o.interpret(statements)
+	if o.didError {
+		return
+	}
+
+	locals := o.resolve(statements)
+
+	if o.didError {
+		return
+	}
+
+	o.interpret("", statements, locals)
 }
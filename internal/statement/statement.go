@@ -13,8 +13,13 @@ type Visitor interface {
 	VisitIfStatement(*IfStatement) (interface{}, error)
 	VisitWhileStatement(*WhileStatement) (interface{}, error)
 	VisitBreakStatement(*BreakStatement) (interface{}, error)
+	VisitContinueStatement(*ContinueStatement) (interface{}, error)
 	VisitFunctionStatement(*FunctionStatement) (interface{}, error)
 	VisitReturnStatement(*ReturnStatement) (interface{}, error)
+	VisitClassStatement(*ClassStatement) (interface{}, error)
+	VisitImportStatement(*ImportStatement) (interface{}, error)
+	VisitThrowStatement(*ThrowStatement) (interface{}, error)
+	VisitTryStatement(*TryStatement) (interface{}, error)
 }
 
 // Statement represents
@@ -100,15 +105,19 @@ func (i *IfStatement) Accept(v Visitor) (interface{}, error) {
 	return v.VisitIfStatement(i)
 }
 
-// WhileStatement represents a while statement
+// WhileStatement represents a while statement, for loops become one too. Increment is the
+// for loop's increment, run after the body and on continue, and Label is empty unless the loop
+// was labeled.
 type WhileStatement struct {
+	Label     tokens.Token
 	Condition expression.Expression
 	Body      Statement
+	Increment expression.Expression
 }
 
 // NewWhileStatement creates a new WhileStatement
-func NewWhileStatement(condition expression.Expression, body Statement) *WhileStatement {
-	return &WhileStatement{condition, body}
+func NewWhileStatement(label tokens.Token, condition expression.Expression, body Statement, increment expression.Expression) *WhileStatement {
+	return &WhileStatement{label, condition, body, increment}
 }
 
 // Accept is the method which invokes this type's functionality
@@ -116,14 +125,15 @@ func (w *WhileStatement) Accept(v Visitor) (interface{}, error) {
 	return v.VisitWhileStatement(w)
 }
 
-// BreakStatement represents a break statement
+// BreakStatement represents a break statement, Label names the loop to leave when it is set
 type BreakStatement struct {
 	Instance tokens.Token
+	Label    tokens.Token
 }
 
 // NewBreakStatement creates a new BreakStatement
-func NewBreakStatement(instance tokens.Token) *BreakStatement {
-	return &BreakStatement{instance}
+func NewBreakStatement(instance, label tokens.Token) *BreakStatement {
+	return &BreakStatement{instance, label}
 }
 
 // Accept is the method which invokes this type's functionality
@@ -131,6 +141,23 @@ func (b *BreakStatement) Accept(v Visitor) (interface{}, error) {
 	return v.VisitBreakStatement(b)
 }
 
+// ContinueStatement represents a continue statement, Label names the loop to go on with when
+// it is set
+type ContinueStatement struct {
+	Instance tokens.Token
+	Label    tokens.Token
+}
+
+// NewContinueStatement creates a new ContinueStatement
+func NewContinueStatement(instance, label tokens.Token) *ContinueStatement {
+	return &ContinueStatement{instance, label}
+}
+
+// Accept is the method which invokes this type's functionality
+func (c *ContinueStatement) Accept(v Visitor) (interface{}, error) {
+	return v.VisitContinueStatement(c)
+}
+
 // FunctionStatement represents a function statement
 type FunctionStatement struct {
 	Name      tokens.Token
@@ -163,3 +190,73 @@ func NewReturnStatement(keyword tokens.Token, value expression.Expression) *Retu
 func (r *ReturnStatement) Accept(v Visitor) (interface{}, error) {
 	return v.VisitReturnStatement(r)
 }
+
+// ClassStatement represents a class declaration, its optional superclass and its methods
+type ClassStatement struct {
+	Name       tokens.Token
+	Superclass *expression.VariableExpression
+	Methods    []*FunctionStatement
+}
+
+// NewClassStatement creates a new ClassStatement
+func NewClassStatement(name tokens.Token, superclass *expression.VariableExpression, methods []*FunctionStatement) *ClassStatement {
+	return &ClassStatement{name, superclass, methods}
+}
+
+// Accept is the method which invokes this type's functionality
+func (c *ClassStatement) Accept(v Visitor) (interface{}, error) {
+	return v.VisitClassStatement(c)
+}
+
+// ImportStatement represents loading another file as a module bound to Name
+type ImportStatement struct {
+	Keyword tokens.Token
+	Path    tokens.Token
+	Name    tokens.Token
+}
+
+// NewImportStatement creates a new ImportStatement
+func NewImportStatement(keyword, path, name tokens.Token) *ImportStatement {
+	return &ImportStatement{keyword, path, name}
+}
+
+// Accept is the method which invokes this type's functionality
+func (i *ImportStatement) Accept(v Visitor) (interface{}, error) {
+	return v.VisitImportStatement(i)
+}
+
+// ThrowStatement represents raising Value as an error
+type ThrowStatement struct {
+	Keyword tokens.Token
+	Value   expression.Expression
+}
+
+// NewThrowStatement creates a new ThrowStatement
+func NewThrowStatement(keyword tokens.Token, value expression.Expression) *ThrowStatement {
+	return &ThrowStatement{keyword, value}
+}
+
+// Accept is the method which invokes this type's functionality
+func (t *ThrowStatement) Accept(v Visitor) (interface{}, error) {
+	return v.VisitThrowStatement(t)
+}
+
+// TryStatement represents running Body and handing any error it raises to the catch clause.
+// Catch and Finally are nil when their clause is left out, CatchName is only set with Catch.
+type TryStatement struct {
+	Keyword   tokens.Token
+	Body      []Statement
+	CatchName tokens.Token
+	Catch     []Statement
+	Finally   []Statement
+}
+
+// NewTryStatement creates a new TryStatement
+func NewTryStatement(keyword tokens.Token, body []Statement, catchName tokens.Token, catch, finally []Statement) *TryStatement {
+	return &TryStatement{keyword, body, catchName, catch, finally}
+}
+
+// Accept is the method which invokes this type's functionality
+func (t *TryStatement) Accept(v Visitor) (interface{}, error) {
+	return v.VisitTryStatement(t)
+}
@@ -1,19 +1,28 @@
 package tokens
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/jparr721/obsidian/internal/diagnostics"
+)
 
 // TokenizerError is an error that occurs during the tokenization process
 type TokenizerError struct {
-	line    int
+	span    diagnostics.Span
 	message string
 }
 
-func newTokenizerError(line int, message string) *TokenizerError {
-	return &TokenizerError{line, message}
+func newTokenizerError(span diagnostics.Span, message string) *TokenizerError {
+	return &TokenizerError{span, message}
 }
 
 func (l *TokenizerError) Error() string {
-	return fmt.Sprintf("[line %d] Error: %s\n", l.line, l.message)
+	return fmt.Sprintf("[line %d] Error: %s", l.span.Line, l.message)
+}
+
+// Diagnostic points at the characters that could not be scanned
+func (l *TokenizerError) Diagnostic() *diagnostics.Diagnostic {
+	return &diagnostics.Diagnostic{Code: diagnostics.CodeToken, Message: l.message, Span: l.span}
 }
 
 // TODO(@jparr721) - Add global "did error" state.
@@ -2,17 +2,35 @@ package tokens
 
 import (
 	"fmt"
-	"regexp"
 	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jparr721/obsidian/internal/diagnostics"
 )
 
-// Tokenizer represents the PL tokenizer
+// Tokenizer represents the PL tokenizer. It walks the source a rune at a time, start and current
+// are byte offsets into src.
 type Tokenizer struct {
 	src     string
 	Tokens  []Token
 	start   int
 	current int
 	line    int
+
+	// file names the source in the tokens' positions
+	file string
+
+	// interpolations tracks each ${ still open, innermost last
+	interpolations []interpolation
+}
+
+// interpolation counts the braces opened inside a ${ so the } that closes it can be found
+type interpolation struct {
+	depth  int
+	line   int
+	offset int
 }
 
 // NewTokenizer creates a new tokenizer from a source string of values
@@ -23,9 +41,16 @@ func NewTokenizer(src string) *Tokenizer {
 		start:   0,
 		current: 0,
 		line:    1,
+
+		interpolations: make([]interpolation, 0),
 	}
 }
 
+// SetFile names the file the source came from, it is recorded in every token
+func (t *Tokenizer) SetFile(file string) {
+	t.file = file
+}
+
 // ScanTokens scans the internal token array and reports errors, or the scanned tokens if successful
 func (t *Tokenizer) ScanTokens() ([]Token, *TokenizerError) {
 	for !t.end() {
@@ -39,98 +64,149 @@ func (t *Tokenizer) ScanTokens() ([]Token, *TokenizerError) {
 
 	}
 
-	t.Tokens = append(t.Tokens, NewToken(TokenEOF, "", nil, t.line))
+	if len(t.interpolations) > 0 {
+		open := t.interpolations[len(t.interpolations)-1]
+		return nil, t.error(open.offset, open.offset+2, open.line, "Unterminated string interpolation.")
+	}
+
+	t.start = t.current
+	t.addToken(TokenEOF, nil)
 	return t.Tokens, nil
 }
 
 func (t *Tokenizer) scanToken() *TokenizerError {
 	c := t.next()
 	switch c {
-	case "(":
+	case '(':
 		t.addToken(TokenOparen, nil)
 		break
-	case ")":
+	case ')':
 		t.addToken(TokenCparen, nil)
 		break
-	case "{":
+	case '[':
+		t.addToken(TokenObracket, nil)
+		break
+	case ']':
+		t.addToken(TokenCbracket, nil)
+		break
+	case '{':
+		if len(t.interpolations) > 0 {
+			t.interpolations[len(t.interpolations)-1].depth++
+		}
+
 		t.addToken(TokenOsquiggle, nil)
 		break
-	case "}":
+	case '}':
+		if n := len(t.interpolations) - 1; n >= 0 {
+			// The brace closing a ${ resumes the string it interrupted
+			if t.interpolations[n].depth == 0 {
+				if t.Tokens[len(t.Tokens)-1].Variant == TokenInterpolation {
+					return t.error(t.start, t.current, t.line, "Empty string interpolation.")
+				}
+
+				t.interpolations = t.interpolations[:n]
+				return t.parseString()
+			}
+
+			t.interpolations[n].depth--
+		}
+
 		t.addToken(TokenCsquiggle, nil)
 		break
-	case ",":
+	case ',':
 		t.addToken(TokenComma, nil)
 		break
-	case ".":
+	case '.':
 		t.addToken(TokenDot, nil)
 		break
-	case "-":
+	case '-':
 		t.addToken(TokenMinus, nil)
 		break
-	case "+":
+	case '+':
 		t.addToken(TokenPlus, nil)
 		break
-	case ";":
+	case ';':
 		t.addToken(TokenSemi, nil)
 		break
-	case "*":
+	case ':':
+		t.addToken(TokenColon, nil)
+		break
+	case '*':
 		t.addToken(TokenStar, nil)
 		break
-	case "!":
-		if t.match("=") {
+	case '%':
+		t.addToken(TokenModulo, nil)
+		break
+	case '&':
+		t.addToken(TokenAmpersand, nil)
+		break
+	case '|':
+		t.addToken(TokenPipe, nil)
+		break
+	case '^':
+		t.addToken(TokenCaret, nil)
+		break
+	case '!':
+		if t.match('=') {
 			t.addToken(TokenBangEqual, nil)
 		} else {
 			t.addToken(TokenBang, nil)
 		}
 		break
-	case "=":
-		if t.match("=") {
+	case '=':
+		if t.match('=') {
 			t.addToken(TokenEqualEqual, nil)
 		} else {
 			t.addToken(TokenEqual, nil)
 		}
 		break
-	case "<":
-		if t.match("=") {
+	case '<':
+		if t.match('=') {
 			t.addToken(TokenLessEqual, nil)
+		} else if t.match('<') {
+			t.addToken(TokenLessLess, nil)
 		} else {
 			t.addToken(TokenLess, nil)
 		}
 		break
-	case ">":
-		if t.match("=") {
+	case '>':
+		if t.match('=') {
 			t.addToken(TokenGreaterEqual, nil)
+		} else if t.match('>') {
+			t.addToken(TokenGreaterGreater, nil)
 		} else {
 			t.addToken(TokenGreater, nil)
 		}
 		break
-	case "/":
-		if t.match("/") {
-			for t.peek() != "\n" && !t.end() {
+	case '/':
+		if t.match('/') {
+			for t.peek() != '\n' && !t.end() {
 				t.next()
 			}
 		} else {
 			t.addToken(TokenSlash, nil)
 		}
 		break
-	case " ":
-	case "":
-	case "\r":
-	case "\t":
+	case ' ', '\r', '\t', '\uFEFF':
 		break
-	case "\n":
+	case utf8.RuneError:
+		return t.error(t.start, t.current, t.line, "Invalid UTF-8 in source.")
+	case '\n':
 		t.line++
 		break
-	case "\"":
-		t.parseString()
-		break
+	case '"':
+		return t.parseString()
+	case '`':
+		return t.parseRawString()
 	default:
 		if t.isDigit(c) {
-			t.parseNumber()
+			if err := t.parseNumber(); err != nil {
+				return err
+			}
 		} else if t.isAlphaOrUnderscore(c) {
 			t.parseIdentifier()
 		} else {
-			return newTokenizerError(t.line, fmt.Sprintf("Unexpected character: %s", c))
+			return t.error(t.start, t.current, t.line, fmt.Sprintf("Unexpected character: %s", string(c)))
 		}
 		break
 	}
@@ -138,22 +214,18 @@ func (t *Tokenizer) scanToken() *TokenizerError {
 	return nil
 }
 
-func (t *Tokenizer) isAlphaOrUnderscore(c string) bool {
-	reg, err := regexp.Compile("^[a-zA-Z0-9_]+")
-
-	if err != nil {
-		newTokenizerError(t.line, err.Error())
-		return false
-	}
+// isAlphaOrUnderscore reports whether c can appear in an identifier, which is any unicode letter
+// or digit. Digits can't start one because numbers are scanned first.
+func (t *Tokenizer) isAlphaOrUnderscore(c rune) bool {
+	return c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
 
-	return reg.MatchString(c)
+func (t *Tokenizer) isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
 }
 
-func (t *Tokenizer) isDigit(c string) bool {
-	if _, err := strconv.Atoi(c); err == nil {
-		return true
-	}
-	return false
+func (t *Tokenizer) isHexDigit(c rune) bool {
+	return t.isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
 }
 
 func (t *Tokenizer) parseIdentifier() {
@@ -172,87 +244,236 @@ func (t *Tokenizer) parseIdentifier() {
 	t.addToken(TokenType, nil)
 }
 
-func (t *Tokenizer) parseNumber() {
+// parseNumber scans a number literal, literals with a decimal point are float64 and the rest
+// are int64
+func (t *Tokenizer) parseNumber() *TokenizerError {
 	for t.isDigit(t.peek()) {
 		t.next()
 	}
 
 	// Check for decimal
-	if t.peek() == "." && t.isDigit(t.peekNext()) {
+	if t.peek() == '.' && t.isDigit(t.peekNext()) {
 		// eat the decimal
 		t.next()
 
 		for t.isDigit(t.peek()) {
 			t.next()
 		}
+
+		value, err := strconv.ParseFloat(t.src[t.start:t.current], 64)
+
+		if err != nil {
+			return t.error(t.start, t.current, t.line, fmt.Sprintf("Invalid number: %s", t.src[t.start:t.current]))
+		}
+
+		t.addToken(TokenNumber, value)
+		return nil
 	}
 
-	value, err := strconv.ParseFloat(t.src[t.start:t.current], 64)
+	value, err := strconv.ParseInt(t.src[t.start:t.current], 10, 64)
 
 	if err != nil {
-		newTokenizerError(t.line, err.Error())
+		return t.error(t.start, t.current, t.line, fmt.Sprintf("Integer literal too large: %s", t.src[t.start:t.current]))
 	}
 
 	t.addToken(TokenNumber, value)
+	return nil
+}
+
+// escapes maps the character after a backslash to the character it stands for
+var escapes = map[rune]rune{
+	'n':  '\n',
+	't':  '\t',
+	'r':  '\r',
+	'0':  0,
+	'"':  '"',
+	'\'': '\'',
+	'\\': '\\',
+	'$':  '$',
 }
 
-func (t *Tokenizer) parseString() {
-	for t.peek() != "\"" && !t.end() {
-		if t.peek() == "\\n" {
+// parseString scans a double quoted string, which may span lines and contain escape sequences.
+// A ${ ends the current segment as an interpolation token, the expression inside is scanned as
+// normal tokens and the closing } picks the string back up.
+func (t *Tokenizer) parseString() *TokenizerError {
+	startLine := t.line
+
+	var value strings.Builder
+	for t.peek() != '"' && !t.end() {
+		c := t.next()
+
+		switch c {
+		case '\n':
 			t.line++
+			value.WriteRune(c)
+		case '\\':
+			escaped, err := t.parseEscape()
+
+			if err != nil {
+				return err
+			}
+
+			value.WriteRune(escaped)
+		case '$':
+			if t.match('{') {
+				t.addTokenOnLine(TokenInterpolation, value.String(), startLine)
+				t.interpolations = append(t.interpolations, interpolation{0, t.line, t.current - 2})
+				return nil
+			}
+
+			value.WriteRune(c)
+		default:
+			value.WriteRune(c)
 		}
-		t.next()
 	}
 
 	if t.end() {
-		newTokenizerError(t.line, "Unexpected string.")
-		return
+		return t.error(t.start, t.start+1, startLine, "Unterminated string.")
 	}
 
+	// The closing quote
 	t.next()
 
-	// Trim quotes
-	value := t.src[t.start+1 : t.current-1]
-	t.addToken(TokenString, value)
+	t.addTokenOnLine(TokenString, value.String(), startLine)
+	return nil
 }
 
-func (t *Tokenizer) peek() string {
+// parseEscape reads the escape sequence after a backslash and returns the character it means
+func (t *Tokenizer) parseEscape() (rune, *TokenizerError) {
+	backslash := t.current - 1
+
 	if t.end() {
-		return "\\0"
+		return 0, t.error(backslash, t.current, t.line, "Unterminated string.")
+	}
+
+	c := t.next()
+
+	if escaped, ok := escapes[c]; ok {
+		return escaped, nil
+	}
+
+	if c != 'u' {
+		return 0, t.error(backslash, t.current, t.line, fmt.Sprintf("Invalid escape sequence: \\%s", string(c)))
+	}
+
+	// \u{XXXX} takes one to six hex digits naming a unicode code point
+	start := t.current
+	if !t.match('{') {
+		return 0, t.error(backslash, t.current, t.line, "Expected '{' after \\u.")
+	}
+
+	for t.isHexDigit(t.peek()) {
+		t.next()
+	}
+
+	digits := t.src[start+1 : t.current]
+	if !t.match('}') || len(digits) == 0 || len(digits) > 6 {
+		return 0, t.error(backslash, t.current, t.line, fmt.Sprintf("Invalid unicode escape: \\u%s", t.src[start:t.current]))
+	}
+
+	code, _ := strconv.ParseInt(digits, 16, 32)
+	if !utf8.ValidRune(rune(code)) {
+		return 0, t.error(backslash, t.current, t.line, fmt.Sprintf("Invalid unicode code point: \\u%s", t.src[start:t.current]))
 	}
 
-	return string(t.src[t.current])
+	return rune(code), nil
 }
 
-func (t *Tokenizer) peekNext() string {
+// parseRawString scans a backtick string, which keeps everything up to the closing backtick
+// as written, newlines and backslashes included
+func (t *Tokenizer) parseRawString() *TokenizerError {
+	startLine := t.line
+
+	for t.peek() != '`' && !t.end() {
+		if t.next() == '\n' {
+			t.line++
+		}
+	}
+
 	if t.end() {
-		return "\\0"
+		return t.error(t.start, t.start+1, startLine, "Unterminated raw string.")
 	}
 
-	return string(t.src[t.current+1])
+	t.next()
+
+	// Trim the backticks, carriage returns are dropped so files with windows line endings
+	// produce the same string
+	value := strings.Replace(t.src[t.start+1:t.current-1], "\r", "", -1)
+	t.addTokenOnLine(TokenString, value, startLine)
+	return nil
 }
 
-func (t *Tokenizer) match(expected string) bool {
+func (t *Tokenizer) peek() rune {
 	if t.end() {
-		return false
+		return 0
+	}
+
+	c, _ := utf8.DecodeRuneInString(t.src[t.current:])
+	return c
+}
+
+func (t *Tokenizer) peekNext() rune {
+	if t.end() {
+		return 0
+	}
+
+	_, size := utf8.DecodeRuneInString(t.src[t.current:])
+	if t.current+size >= len(t.src) {
+		return 0
 	}
 
-	if string(t.src[t.current]) != expected {
+	c, _ := utf8.DecodeRuneInString(t.src[t.current+size:])
+	return c
+}
+
+func (t *Tokenizer) match(expected rune) bool {
+	if t.end() || t.peek() != expected {
 		return false
 	}
 
-	t.current++
+	t.next()
 	return true
 }
 
 func (t *Tokenizer) addToken(TokenType TokenType, literal interface{}) {
+	t.addTokenOnLine(TokenType, literal, t.line)
+}
+
+// addTokenOnLine adds a token that began on an earlier line, such as a multi-line string
+func (t *Tokenizer) addTokenOnLine(TokenType TokenType, literal interface{}, line int) {
 	text := t.src[t.start:t.current]
-	t.Tokens = append(t.Tokens, NewToken(TokenType, text, literal, t.line))
+	token := NewToken(TokenType, text, literal, line)
+
+	token.File = t.file
+	token.Offset = t.start
+	token.Column = t.column(t.start)
+	token.Length = utf8.RuneCountInString(text)
+
+	t.Tokens = append(t.Tokens, token)
+}
+
+// column is the column of the rune at offset, counted from the start of its line
+func (t *Tokenizer) column(offset int) int {
+	lineStart := strings.LastIndexByte(t.src[:offset], '\n') + 1
+	return utf8.RuneCountInString(t.src[lineStart:offset]) + 1
+}
+
+// error reports a problem with src[offset:end], which begins on line
+func (t *Tokenizer) error(offset, end, line int, message string) *TokenizerError {
+	span := diagnostics.Span{
+		File:   t.file,
+		Line:   line,
+		Column: t.column(offset),
+		Length: utf8.RuneCountInString(t.src[offset:end]),
+	}
+
+	return newTokenizerError(span, message)
 }
 
-func (t *Tokenizer) next() string {
-	t.current++
-	return string(t.src[t.current-1])
+func (t *Tokenizer) next() rune {
+	c, size := utf8.DecodeRuneInString(t.src[t.current:])
+	t.current += size
+	return c
 }
 
 func (t *Tokenizer) end() bool {
@@ -0,0 +1,206 @@
+package tokens
+
+import (
+	"testing"
+)
+
+type tokenizeTest struct {
+	Name     string
+	Source   string
+	Expected []interface{}
+}
+
+// scanStrings tokenizes src and returns the literal of each string token
+func scanStrings(t *testing.T, src string) []interface{} {
+	toks, err := NewTokenizer(src).ScanTokens()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	literals := make([]interface{}, 0)
+	for _, token := range toks {
+		if token.Variant == TokenString {
+			literals = append(literals, token.Literal)
+		}
+	}
+
+	return literals
+}
+
+func TestStringLiterals(t *testing.T) {
+	tests := []tokenizeTest{
+		{Name: "Plain string", Source: `"hello"`, Expected: []interface{}{"hello"}},
+		{Name: "Escapes", Source: `"a\nb\tc\r\"q\" \\ \'s\0"`, Expected: []interface{}{"a\nb\tc\r\"q\" \\ 's\x00"}},
+		{Name: "Unicode escapes", Source: `"\u{48}\u{e9}\u{1F600}"`, Expected: []interface{}{"Hé😀"}},
+		{Name: "UTF-8 is kept whole", Source: `"héllo, 世界"`, Expected: []interface{}{"héllo, 世界"}},
+		{Name: "Multi-line string", Source: "\"one\ntwo\"", Expected: []interface{}{"one\ntwo"}},
+		{Name: "Raw string", Source: "`C:\\dir\\n \"quoted\"\nnext`", Expected: []interface{}{"C:\\dir\\n \"quoted\"\nnext"}},
+		{Name: "Raw string drops carriage returns", Source: "`a\r\nb`", Expected: []interface{}{"a\nb"}},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+		got := scanStrings(t, test.Source)
+
+		if len(got) != len(test.Expected) || got[0] != test.Expected[0] {
+			t.Errorf("%s: expected %q but got %q", test.Name, test.Expected, got)
+		}
+	}
+}
+
+func TestLineNumbers(t *testing.T) {
+	toks, err := NewTokenizer("var a = \"one\ntwo\";\nvar b = `x\ny\nz`;\nb;").ScanTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []int{1, 1, 1, 1, 2, 3, 3, 3, 3, 5, 6, 6, 6}
+	if len(toks) != len(expected) {
+		t.Fatalf("expected %d tokens but got %d", len(expected), len(toks))
+	}
+
+	for n, token := range toks {
+		if token.Line != expected[n] {
+			t.Errorf("token %d %q: expected line %d but got %d", n, token.Lexeme, expected[n], token.Line)
+		}
+	}
+}
+
+func TestPositions(t *testing.T) {
+	tokenizer := NewTokenizer("var é = 1;\n  print é <= \"a\nb\";")
+	tokenizer.SetFile("main.ob")
+
+	toks, err := tokenizer.ScanTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Offsets are bytes while columns and lengths are runes, so é moves them apart
+	expected := []Token{
+		{Lexeme: "var", Line: 1, Offset: 0, Column: 1, Length: 3},
+		{Lexeme: "é", Line: 1, Offset: 4, Column: 5, Length: 1},
+		{Lexeme: "=", Line: 1, Offset: 7, Column: 7, Length: 1},
+		{Lexeme: "1", Line: 1, Offset: 9, Column: 9, Length: 1},
+		{Lexeme: ";", Line: 1, Offset: 10, Column: 10, Length: 1},
+		{Lexeme: "print", Line: 2, Offset: 14, Column: 3, Length: 5},
+		{Lexeme: "é", Line: 2, Offset: 20, Column: 9, Length: 1},
+		{Lexeme: "<=", Line: 2, Offset: 23, Column: 11, Length: 2},
+		{Lexeme: "\"a\nb\"", Line: 2, Offset: 26, Column: 14, Length: 5},
+		{Lexeme: ";", Line: 3, Offset: 31, Column: 3, Length: 1},
+		{Lexeme: "", Line: 3, Offset: 32, Column: 4, Length: 0},
+	}
+
+	if len(toks) != len(expected) {
+		t.Fatalf("expected %d tokens but got %d", len(expected), len(toks))
+	}
+
+	for n, token := range toks {
+		want := expected[n]
+
+		if token.File != "main.ob" || token.Lexeme != want.Lexeme || token.Line != want.Line ||
+			token.Offset != want.Offset || token.Column != want.Column || token.Length != want.Length {
+			t.Errorf("token %d: expected %q at %d:%d offset %d length %d but got %q at %d:%d offset %d length %d in %q",
+				n, want.Lexeme, want.Line, want.Column, want.Offset, want.Length,
+				token.Lexeme, token.Line, token.Column, token.Offset, token.Length, token.File)
+		}
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	tests := []tokenizeTest{
+		{Name: "Unexpected character", Source: "var a = 1 @ 2;", Expected: []interface{}{1, 11, 1}},
+		{Name: "Invalid escape", Source: "print\n  \"ab\\q\";", Expected: []interface{}{2, 6, 2}},
+		{Name: "Unterminated string", Source: "print 1;\nprint \"open\n;", Expected: []interface{}{2, 7, 1}},
+		{Name: "Unterminated interpolation", Source: "print \"a ${b", Expected: []interface{}{1, 10, 2}},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+		_, err := NewTokenizer(test.Source).ScanTokens()
+
+		if err == nil {
+			t.Errorf("%s: expected an error", test.Name)
+			continue
+		}
+
+		span := err.Diagnostic().Span
+		if got := []interface{}{span.Line, span.Column, span.Length}; got[0] != test.Expected[0] || got[1] != test.Expected[1] || got[2] != test.Expected[2] {
+			t.Errorf("%s: expected the error at %v but got %v", test.Name, test.Expected, got)
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	toks, err := NewTokenizer("var café = π_2;").ScanTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if toks[1].Variant != TokenIdentifier || toks[1].Lexeme != "café" {
+		t.Errorf("expected identifier café but got %v %q", toks[1].Variant, toks[1].Lexeme)
+	}
+
+	if toks[3].Variant != TokenIdentifier || toks[3].Lexeme != "π_2" {
+		t.Errorf("expected identifier π_2 but got %v %q", toks[3].Variant, toks[3].Lexeme)
+	}
+}
+
+func TestTokenizerErrors(t *testing.T) {
+	tests := []tokenizeTest{
+		{Name: "Unterminated string", Source: "print 1;\nprint \"open\n;", Expected: []interface{}{"[line 2] Error: Unterminated string."}},
+		{Name: "Unterminated raw string", Source: "`open", Expected: []interface{}{"[line 1] Error: Unterminated raw string."}},
+		{Name: "Unknown escape", Source: `"\q"`, Expected: []interface{}{"[line 1] Error: Invalid escape sequence: \\q"}},
+		{Name: "Escape at end of file", Source: `"\`, Expected: []interface{}{"[line 1] Error: Unterminated string."}},
+		{Name: "Unicode escape without braces", Source: `"\u0041"`, Expected: []interface{}{"[line 1] Error: Expected '{' after \\u."}},
+		{Name: "Empty unicode escape", Source: `"\u{}"`, Expected: []interface{}{"[line 1] Error: Invalid unicode escape: \\u{}"}},
+		{Name: "Surrogate code point", Source: `"\u{D800}"`, Expected: []interface{}{"[line 1] Error: Invalid unicode code point: \\u{D800}"}},
+		{Name: "Unexpected character", Source: "var a = 1 @ 2;", Expected: []interface{}{"[line 1] Error: Unexpected character: @"}},
+		{Name: "Invalid UTF-8", Source: "var a = \xff;", Expected: []interface{}{"[line 1] Error: Invalid UTF-8 in source."}},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+		_, err := NewTokenizer(test.Source).ScanTokens()
+
+		if err == nil || err.Error() != test.Expected[0] {
+			t.Errorf("%s: expected error %q but got %v", test.Name, test.Expected[0], err)
+		}
+	}
+}
+
+func TestInterpolation(t *testing.T) {
+	toks, err := NewTokenizer(`"a ${x + {"k": "}"}["k"]} b ${"${y}"}"`).ScanTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []TokenType{
+		TokenInterpolation, TokenIdentifier, TokenPlus, TokenOsquiggle, TokenString, TokenColon, TokenString,
+		TokenCsquiggle, TokenObracket, TokenString, TokenCbracket,
+		TokenInterpolation, TokenInterpolation, TokenIdentifier, TokenString, TokenString, TokenEOF,
+	}
+
+	if len(toks) != len(expected) {
+		t.Fatalf("expected %d tokens but got %d: %v", len(expected), len(toks), toks)
+	}
+
+	for n, token := range toks {
+		if token.Variant != expected[n] {
+			t.Errorf("token %d %q: expected %s but got %s", n, token.Lexeme, expected[n], token.Variant)
+		}
+	}
+
+	if toks[0].Literal != "a " || toks[11].Literal != " b " || toks[15].Literal != "" {
+		t.Errorf("unexpected segments %q, %q and %q", toks[0].Literal, toks[11].Literal, toks[15].Literal)
+	}
+
+	_, err = NewTokenizer(`print "empty ${}";`).ScanTokens()
+	if err == nil || err.Error() != "[line 1] Error: Empty string interpolation." {
+		t.Errorf("expected an empty interpolation error but got %v", err)
+	}
+
+	_, err = NewTokenizer("print \"open ${x\n;").ScanTokens()
+	if err == nil || err.Error() != "[line 1] Error: Unterminated string interpolation." {
+		t.Errorf("expected an unterminated interpolation error but got %v", err)
+	}
+}
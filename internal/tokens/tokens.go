@@ -1,26 +1,39 @@
 package tokens
 
+import "github.com/jparr721/obsidian/internal/diagnostics"
+
 // TokenType represents a token by enum position
 type TokenType int
 
-// Token represents a token value in a file
+// Token represents a token value in a file. Offset is in bytes from the start of the source,
+// Column and Length count runes so they line up with the text as it is displayed.
 type Token struct {
 	Variant TokenType
 	Lexeme  string
 	Literal interface{}
 	Line    int
+
+	File   string
+	Offset int
+	Column int
+	Length int
 }
 
 // NewToken creates a new token from a given input sequence of values
 func NewToken(tokenType TokenType, lexeme string, literal interface{}, line int) Token {
 	return Token{
-		tokenType,
-		lexeme,
-		literal,
-		line,
+		Variant: tokenType,
+		Lexeme:  lexeme,
+		Literal: literal,
+		Line:    line,
 	}
 }
 
+// Span is the stretch of source the token was scanned from
+func (t Token) Span() diagnostics.Span {
+	return diagnostics.Span{File: t.File, Line: t.Line, Column: t.Column, Length: t.Length}
+}
+
 const (
 	// TokenOsquiggle represents a Left squiggle bracket
 	TokenOsquiggle TokenType = iota
@@ -34,12 +47,21 @@ const (
 	// TokenCparen Represents A Right Parenthesis
 	TokenCparen
 
+	// TokenObracket Represents A Left Square Bracket
+	TokenObracket
+
+	// TokenCbracket Represents A Right Square Bracket
+	TokenCbracket
+
 	// TokenComma Represents A Comma
 	TokenComma
 
 	// TokenSemi Represents A ;
 	TokenSemi
 
+	// TokenColon Represents A :
+	TokenColon
+
 	// TokenDot Represents A ,
 	TokenDot
 
@@ -58,6 +80,21 @@ const (
 	// TokenModulo Represents A % Operator
 	TokenModulo
 
+	// TokenAmpersand Represents A & Operator
+	TokenAmpersand
+
+	// TokenPipe Represents A | Operator
+	TokenPipe
+
+	// TokenCaret Represents A ^ Operator
+	TokenCaret
+
+	// TokenLessLess Represents A << Operator
+	TokenLessLess
+
+	// TokenGreaterGreater Represents A >> Operator
+	TokenGreaterGreater
+
 	// TokenBang Represents A ! Symbol
 	TokenBang
 
@@ -88,6 +125,9 @@ const (
 	// TokenString Represents A String Value
 	TokenString
 
+	// TokenInterpolation Represents The Part Of A String Before A ${ Expression
+	TokenInterpolation
+
 	// TokenNumber Represents A Numeric Value
 	TokenNumber
 
@@ -142,6 +182,27 @@ const (
 	// TokenBreak Represents The Break Keyword
 	TokenBreak
 
+	// TokenImport Represents The Import Keyword
+	TokenImport
+
+	// TokenAs Represents The As Keyword
+	TokenAs
+
+	// TokenThrow Represents The Throw Keyword
+	TokenThrow
+
+	// TokenTry Represents The Try Keyword
+	TokenTry
+
+	// TokenCatch Represents The Catch Keyword
+	TokenCatch
+
+	// TokenFinally Represents The Finally Keyword
+	TokenFinally
+
+	// TokenContinue Represents The Continue Keyword
+	TokenContinue
+
 	// TokenEOF Represents The End Of File
 	TokenEOF
 
@@ -151,21 +212,99 @@ const (
 
 // Keywords represents all of the keyword types
 var Keywords = map[string]TokenType{
-	"and":    TokenAnd,
-	"class":  TokenClass,
-	"else":   TokenElse,
-	"false":  TokenFalse,
-	"for":    TokenFor,
-	"fun":    TokenFun,
-	"if":     TokenIf,
-	"nil":    TokenNil,
-	"or":     TokenOr,
-	"print":  TokenPrint,
-	"return": TokenReturn,
-	"super":  TokenSuper,
-	"this":   TokenThis,
-	"true":   TokenTrue,
-	"var":    TokenVar,
-	"while":  TokenWhile,
-	"break":  TokenBreak,
+	"and":      TokenAnd,
+	"class":    TokenClass,
+	"else":     TokenElse,
+	"false":    TokenFalse,
+	"for":      TokenFor,
+	"fun":      TokenFun,
+	"if":       TokenIf,
+	"nil":      TokenNil,
+	"or":       TokenOr,
+	"print":    TokenPrint,
+	"return":   TokenReturn,
+	"super":    TokenSuper,
+	"this":     TokenThis,
+	"true":     TokenTrue,
+	"var":      TokenVar,
+	"while":    TokenWhile,
+	"break":    TokenBreak,
+	"import":   TokenImport,
+	"as":       TokenAs,
+	"throw":    TokenThrow,
+	"try":      TokenTry,
+	"catch":    TokenCatch,
+	"finally":  TokenFinally,
+	"continue": TokenContinue,
+}
+
+// tokenNames maps each token type to the name shown in token dumps
+var tokenNames = map[TokenType]string{
+	TokenOsquiggle:      "Osquiggle",
+	TokenCsquiggle:      "Csquiggle",
+	TokenOparen:         "Oparen",
+	TokenCparen:         "Cparen",
+	TokenObracket:       "Obracket",
+	TokenCbracket:       "Cbracket",
+	TokenComma:          "Comma",
+	TokenSemi:           "Semi",
+	TokenColon:          "Colon",
+	TokenDot:            "Dot",
+	TokenPlus:           "Plus",
+	TokenMinus:          "Minus",
+	TokenStar:           "Star",
+	TokenSlash:          "Slash",
+	TokenModulo:         "Modulo",
+	TokenAmpersand:      "Ampersand",
+	TokenPipe:           "Pipe",
+	TokenCaret:          "Caret",
+	TokenLessLess:       "LessLess",
+	TokenGreaterGreater: "GreaterGreater",
+	TokenBang:           "Bang",
+	TokenBangEqual:      "BangEqual",
+	TokenEqual:          "Equal",
+	TokenEqualEqual:     "EqualEqual",
+	TokenGreater:        "Greater",
+	TokenGreaterEqual:   "GreaterEqual",
+	TokenLess:           "Less",
+	TokenLessEqual:      "LessEqual",
+	TokenIdentifier:     "Identifier",
+	TokenString:         "String",
+	TokenInterpolation:  "Interpolation",
+	TokenNumber:         "Number",
+	TokenAnd:            "And",
+	TokenClass:          "Class",
+	TokenElse:           "Else",
+	TokenFalse:          "False",
+	TokenFun:            "Fun",
+	TokenFor:            "For",
+	TokenIf:             "If",
+	TokenNil:            "Nil",
+	TokenOr:             "Or",
+	TokenPrint:          "Print",
+	TokenReturn:         "Return",
+	TokenSuper:          "Super",
+	TokenThis:           "This",
+	TokenTrue:           "True",
+	TokenVar:            "Var",
+	TokenWhile:          "While",
+	TokenBreak:          "Break",
+	TokenImport:         "Import",
+	TokenAs:             "As",
+	TokenThrow:          "Throw",
+	TokenTry:            "Try",
+	TokenCatch:          "Catch",
+	TokenFinally:        "Finally",
+	TokenContinue:       "Continue",
+	TokenEOF:            "EOF",
+	TokenUnknown:        "Unknown",
+}
+
+// String returns the readable name of a token type
+func (t TokenType) String() string {
+	if name, ok := tokenNames[t]; ok {
+		return name
+	}
+
+	return "Unknown"
 }
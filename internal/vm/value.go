@@ -0,0 +1,124 @@
+package vm
+
+import (
+	"fmt"
+
+	"github.com/jparr721/obsidian/internal/bytecode"
+	"github.com/jparr721/obsidian/internal/interpreter"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+// Closure is a compiled function together with the variables it captured
+type Closure struct {
+	Function *bytecode.Function
+	upvalues []*upvalue
+
+	// globals and file belong to the module the function was declared in
+	globals *globals
+	file    string
+}
+
+func newClosure(function *bytecode.Function, globals *globals, file string) *Closure {
+	return &Closure{function, make([]*upvalue, function.UpvalueCount), globals, file}
+}
+
+// globals is the top level scope of a module. The natives and constants sit in a scope of their
+// own beneath it, so a module only exports the names it declares.
+type globals struct {
+	values  map[string]interface{}
+	natives map[string]interface{}
+}
+
+func newGlobals() *globals {
+	natives := interpreter.Constants()
+	for _, native := range interpreter.Natives() {
+		natives[native.Name()] = native
+	}
+
+	return &globals{make(map[string]interface{}), natives}
+}
+
+func (g *globals) get(name string) (interface{}, bool) {
+	if value, ok := g.values[name]; ok {
+		return value, true
+	}
+
+	value, ok := g.natives[name]
+	return value, ok
+}
+
+// set assigns to an existing name in whichever scope holds it
+func (g *globals) set(name string, value interface{}) bool {
+	if _, ok := g.values[name]; ok {
+		g.values[name] = value
+		return true
+	}
+
+	if _, ok := g.natives[name]; ok {
+		g.natives[name] = value
+		return true
+	}
+
+	return false
+}
+
+// token rebuilds the position of the instruction at offset for error reporting
+func (c *Closure) token(offset int) tokens.Token {
+	chunk := c.Function.Chunk
+	span := chunk.Spans[offset]
+
+	return tokens.Token{Line: chunk.Lines[offset], File: c.file, Column: span.Column, Length: span.Length}
+}
+
+func (c *Closure) String() string {
+	return c.Function.String()
+}
+
+// upvalue is a captured variable. It reads through to its stack slot until the slot goes out
+// of scope, then it holds the value itself.
+type upvalue struct {
+	slot   int
+	open   bool
+	closed interface{}
+
+	// next links the open upvalues from the highest slot down
+	next *upvalue
+}
+
+// Class represents a class value, calling it constructs a new instance
+type Class struct {
+	Name    string
+	methods map[string]*Closure
+}
+
+func newClass(name string) *Class {
+	return &Class{name, make(map[string]*Closure)}
+}
+
+func (c *Class) String() string {
+	return c.Name
+}
+
+// Instance represents an object created from a class
+type Instance struct {
+	class  *Class
+	fields map[string]interface{}
+}
+
+func newInstance(class *Class) *Instance {
+	return &Instance{class, make(map[string]interface{})}
+}
+
+func (in *Instance) String() string {
+	return fmt.Sprintf("<%s instance>", in.class.Name)
+}
+
+// BoundMethod is a method read off of an instance, calling it passes the instance as "this"
+type BoundMethod struct {
+	receiver interface{}
+	method   *Closure
+}
+
+func (b *BoundMethod) String() string {
+	return b.method.String()
+}
@@ -0,0 +1,689 @@
+package vm
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/jparr721/obsidian/internal/bytecode"
+	"github.com/jparr721/obsidian/internal/compiler"
+	"github.com/jparr721/obsidian/internal/interpreter"
+	"github.com/jparr721/obsidian/internal/module"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+// maxFrames bounds the call depth so runaway recursion fails with an error
+const maxFrames = 1024
+
+// frame is a single function activation, base is the stack slot holding the callee
+type frame struct {
+	closure *Closure
+	ip      int
+	base    int
+}
+
+// handler is an installed try, an error raised while it is installed resumes at ip in the frame
+// at index frame with the stack cut back to height
+type handler struct {
+	frame  int
+	ip     int
+	height int
+}
+
+// VM is the stack based virtual machine backend. It shares its values and natives with the
+// tree walking interpreter.
+type VM struct {
+	stack    []interface{}
+	frames   []frame
+	globals  *globals
+	handlers []handler
+
+	// openUpvalues are captured variables still living on the stack, highest slot first
+	openUpvalues *upvalue
+
+	// out is where print statements write to
+	out io.Writer
+
+	// loader finds and caches modules, file is the file whose top level is running
+	loader *module.Loader
+	file   string
+
+	// host is the interpreter natives are called with, it carries the state they share
+	host *interpreter.Interpreter
+}
+
+func NewVM() *VM {
+	return &VM{make([]interface{}, 0, 256), make([]frame, 0, 64), newGlobals(), make([]handler, 0), nil, os.Stdout, module.NewLoader(), "", interpreter.NewInterpreter()}
+}
+
+// SetLoader replaces the module loader, for example to add search paths
+func (vm *VM) SetLoader(loader *module.Loader) {
+	vm.loader = loader
+}
+
+// SetFile records the path of the file being run, imports are relative to it
+func (vm *VM) SetFile(file string) {
+	vm.file = file
+}
+
+// SetOutput redirects print statements to w
+func (vm *VM) SetOutput(w io.Writer) {
+	vm.out = w
+}
+
+// Run executes a compiled script, globals it defines stay around for the next run
+func (vm *VM) Run(script *bytecode.Function) (err error) {
+	// Object files are verified before they run, should bad code slip through anyway it fails
+	// the run rather than the whole process
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = vm.crash(recovered)
+			vm.reset()
+		}
+	}()
+
+	closure := newClosure(script, vm.globals, vm.file)
+	vm.push(closure)
+
+	err = vm.call(0, closure, 0)
+
+	if err == nil {
+		err = vm.run(0)
+	}
+
+	if err != nil {
+		vm.reset()
+	}
+
+	return err
+}
+
+// reset drops whatever a failed run left on the stacks so the machine can run again
+func (vm *VM) reset() {
+	vm.stack = vm.stack[:0]
+	vm.frames = vm.frames[:0]
+	vm.handlers = vm.handlers[:0]
+	vm.openUpvalues = nil
+}
+
+func (vm *VM) push(value interface{}) {
+	vm.stack = append(vm.stack, value)
+}
+
+func (vm *VM) pop() interface{} {
+	value := vm.stack[len(vm.stack)-1]
+	vm.stack = vm.stack[:len(vm.stack)-1]
+	return value
+}
+
+func (vm *VM) peek(distance int) interface{} {
+	return vm.stack[len(vm.stack)-1-distance]
+}
+
+// runtimeError reports message at the source position of the instruction that is running,
+// along with the calls that led to it
+func (vm *VM) runtimeError(instruction int, message string) error {
+	f := vm.frames[len(vm.frames)-1]
+	return interpreter.NewRuntimeError(f.closure.token(instruction), message).WithTrace(vm.trace())
+}
+
+// crash turns a panic raised by malformed code into a runtime error at the instruction that was
+// running
+func (vm *VM) crash(recovered interface{}) error {
+	message := fmt.Sprintf("Invalid bytecode: %v.", recovered)
+
+	if len(vm.frames) == 0 {
+		return interpreter.NewRuntimeError(tokens.Token{File: vm.file}, message)
+	}
+
+	f := vm.frames[len(vm.frames)-1]
+	instruction := f.ip - 1
+
+	if instruction < 0 || instruction >= len(f.closure.Function.Chunk.Code) {
+		return interpreter.NewRuntimeError(tokens.Token{File: f.closure.file}, message)
+	}
+
+	return vm.runtimeError(instruction, message)
+}
+
+// throw raises value at the source position of the instruction that is running
+func (vm *VM) throw(instruction int, value interface{}) error {
+	f := vm.frames[len(vm.frames)-1]
+	err := interpreter.Throw(value, f.closure.token(instruction))
+
+	// A rethrown error already knows where it came from
+	if err.Trace() == nil {
+		err.WithTrace(vm.trace())
+	}
+
+	return err
+}
+
+// trace lists the calls in progress the way the interpreter records them
+func (vm *VM) trace() []interpreter.Frame {
+	frames := make([]interpreter.Frame, 0, len(vm.frames)-1)
+	for n := 1; n < len(vm.frames); n++ {
+		caller, callee := vm.frames[n-1], vm.frames[n].closure
+
+		// Only the top level of a module runs without a name, it is called from its import
+		name := callee.Function.Name
+		if name == "" {
+			name = "<script>"
+		}
+
+		// A caller's ip sits just past the call it is waiting on
+		frames = append(frames, interpreter.Frame{Name: name, Call: caller.closure.token(caller.ip - 1), Callee: callee})
+	}
+
+	return frames
+}
+
+// runModule compiles and runs a module file with its own globals and hands them back
+func (vm *VM) runModule(file string) (map[string]interface{}, error) {
+	statements, _, err := vm.loader.Parse(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	script, errs := compiler.NewCompiler().Compile(statements)
+
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	globals := newGlobals()
+	closure := newClosure(script, globals, file)
+	depth := len(vm.frames)
+
+	previousFile := vm.file
+	vm.file = file
+	defer func() { vm.file = previousFile }()
+
+	vm.push(closure)
+	if err := vm.call(0, closure, 0); err != nil {
+		return nil, err
+	}
+
+	if err := vm.run(depth); err != nil {
+		return nil, err
+	}
+
+	// Drop the module script's nil result
+	vm.pop()
+	return globals.values, nil
+}
+
+// run executes instructions until the frame count falls back to depth. Runtime errors raised
+// while a try of one of those frames is installed resume at its handler.
+func (vm *VM) run(depth int) error {
+	for {
+		err := vm.execute(depth)
+
+		if err == nil || !vm.catch(err, depth) {
+			return err
+		}
+	}
+}
+
+// catch unwinds to the innermost installed try and pushes the caught error for its catch
+// clause. Tries installed by frames below depth belong to an outer run and are left alone.
+func (vm *VM) catch(err error, depth int) bool {
+	caught, ok := err.(*interpreter.RuntimeError)
+
+	if !ok || len(vm.handlers) == 0 {
+		return false
+	}
+
+	h := vm.handlers[len(vm.handlers)-1]
+
+	if h.frame < depth {
+		return false
+	}
+
+	vm.handlers = vm.handlers[:len(vm.handlers)-1]
+	vm.closeUpvalues(h.height)
+	vm.stack = vm.stack[:h.height]
+	vm.frames = vm.frames[:h.frame+1]
+	vm.frames[h.frame].ip = h.ip
+
+	vm.push(interpreter.Catch(caught, nil))
+	return true
+}
+
+// execute runs the innermost frame until the frame count falls back to depth or an error is
+// raised
+func (vm *VM) execute(depth int) error {
+	f := &vm.frames[len(vm.frames)-1]
+	code := f.closure.Function.Chunk.Code
+	constants := f.closure.Function.Chunk.Constants
+	globals := f.closure.globals
+
+	readShort := func() int {
+		f.ip += 2
+		return int(code[f.ip-2])<<8 | int(code[f.ip-1])
+	}
+
+	for {
+		instruction := f.ip
+		op := bytecode.OpCode(code[f.ip])
+		f.ip++
+
+		switch op {
+		case bytecode.OpConstant:
+			vm.push(constants[readShort()])
+		case bytecode.OpNil:
+			vm.push(nil)
+		case bytecode.OpTrue:
+			vm.push(true)
+		case bytecode.OpFalse:
+			vm.push(false)
+		case bytecode.OpPop:
+			vm.pop()
+		case bytecode.OpGetLocal:
+			vm.push(vm.stack[f.base+int(code[f.ip])])
+			f.ip++
+		case bytecode.OpSetLocal:
+			vm.stack[f.base+int(code[f.ip])] = vm.peek(0)
+			f.ip++
+		case bytecode.OpGetGlobal:
+			name := constants[readShort()].(string)
+			value, ok := globals.get(name)
+
+			if !ok {
+				return vm.runtimeError(instruction, fmt.Sprintf("Undefined variable '%s'", name))
+			}
+
+			vm.push(value)
+		case bytecode.OpDefineGlobal:
+			globals.values[constants[readShort()].(string)] = vm.pop()
+		case bytecode.OpSetGlobal:
+			name := constants[readShort()].(string)
+
+			if !globals.set(name, vm.peek(0)) {
+				return vm.runtimeError(instruction, fmt.Sprintf("Undefined variable '%s'", name))
+			}
+		case bytecode.OpGetUpvalue:
+			vm.push(vm.getUpvalue(f.closure.upvalues[code[f.ip]]))
+			f.ip++
+		case bytecode.OpSetUpvalue:
+			vm.setUpvalue(f.closure.upvalues[code[f.ip]], vm.peek(0))
+			f.ip++
+		case bytecode.OpGetProperty:
+			name := constants[readShort()].(string)
+			if imported, ok := vm.peek(0).(*module.Module); ok {
+				value, ok := imported.Get(name)
+
+				if !ok {
+					return vm.runtimeError(instruction, fmt.Sprintf("Module '%s' has no export '%s'.", imported.Name, name))
+				}
+
+				vm.stack[len(vm.stack)-1] = value
+				break
+			}
+
+			if caught, ok := vm.peek(0).(*interpreter.ErrorValue); ok {
+				value, ok := caught.Get(name)
+
+				if !ok {
+					return vm.runtimeError(instruction, fmt.Sprintf("Undefined property '%s'.", name))
+				}
+
+				vm.stack[len(vm.stack)-1] = value
+				break
+			}
+
+			instance, ok := vm.peek(0).(*Instance)
+
+			if !ok {
+				return vm.runtimeError(instruction, "Only instances have properties.")
+			}
+
+			// Fields shadow methods of the same name
+			if value, ok := instance.fields[name]; ok {
+				vm.stack[len(vm.stack)-1] = value
+				break
+			}
+
+			method, ok := instance.class.methods[name]
+
+			if !ok {
+				return vm.runtimeError(instruction, fmt.Sprintf("Undefined property '%s'.", name))
+			}
+
+			vm.stack[len(vm.stack)-1] = &BoundMethod{instance, method}
+		case bytecode.OpSetProperty:
+			name := constants[readShort()].(string)
+			value := vm.pop()
+			instance, ok := vm.pop().(*Instance)
+
+			if !ok {
+				return vm.runtimeError(instruction, "Only instances have fields.")
+			}
+
+			instance.fields[name] = value
+			vm.push(value)
+		case bytecode.OpGetSuper:
+			name := constants[readShort()].(string)
+			superclass := vm.pop().(*Class)
+			method, ok := superclass.methods[name]
+
+			if !ok {
+				return vm.runtimeError(instruction, fmt.Sprintf("Undefined property '%s'.", name))
+			}
+
+			vm.stack[len(vm.stack)-1] = &BoundMethod{vm.peek(0), method}
+		case bytecode.OpGetIndex:
+			key := vm.pop()
+			indexable, ok := vm.peek(0).(interpreter.Indexable)
+
+			if !ok {
+				return vm.runtimeError(instruction, "Only lists and maps can be indexed.")
+			}
+
+			value, err := indexable.Index(key)
+
+			if err != nil {
+				return vm.runtimeError(instruction, err.Error())
+			}
+
+			vm.stack[len(vm.stack)-1] = value
+		case bytecode.OpSetIndex:
+			value := vm.pop()
+			key := vm.pop()
+			indexable, ok := vm.peek(0).(interpreter.Indexable)
+
+			if !ok {
+				return vm.runtimeError(instruction, "Only lists and maps can be indexed.")
+			}
+
+			if err := indexable.SetIndex(key, value); err != nil {
+				return vm.runtimeError(instruction, err.Error())
+			}
+
+			vm.stack[len(vm.stack)-1] = value
+		case bytecode.OpEqual:
+			b := vm.pop()
+			vm.stack[len(vm.stack)-1] = interpreter.IsEqual(vm.peek(0), b)
+		case bytecode.OpGreater, bytecode.OpGreaterEqual, bytecode.OpLess, bytecode.OpLessEqual,
+			bytecode.OpAdd, bytecode.OpSubtract, bytecode.OpMultiply, bytecode.OpDivide, bytecode.OpModulo,
+			bytecode.OpBitAnd, bytecode.OpBitOr, bytecode.OpBitXor, bytecode.OpShiftLeft, bytecode.OpShiftRight:
+			value, err := interpreter.Binary(operators[op], vm.peek(1), vm.peek(0))
+
+			if err != nil {
+				return vm.runtimeError(instruction, err.Error())
+			}
+
+			vm.pop()
+			vm.stack[len(vm.stack)-1] = value
+		case bytecode.OpNot:
+			vm.stack[len(vm.stack)-1] = !interpreter.IsTruthy(vm.peek(0))
+		case bytecode.OpNegate:
+			value, err := interpreter.Negate(vm.peek(0))
+
+			if err != nil {
+				return vm.runtimeError(instruction, err.Error())
+			}
+
+			vm.stack[len(vm.stack)-1] = value
+		case bytecode.OpPrint:
+			fmt.Fprintln(vm.out, interpreter.Stringify(vm.pop()))
+		case bytecode.OpJump:
+			offset := readShort()
+			f.ip += offset
+		case bytecode.OpJumpIfFalse:
+			offset := readShort()
+
+			if !interpreter.IsTruthy(vm.peek(0)) {
+				f.ip += offset
+			}
+		case bytecode.OpLoop:
+			offset := readShort()
+			f.ip -= offset
+		case bytecode.OpCall:
+			argc := int(code[f.ip])
+			f.ip++
+
+			if err := vm.callValue(instruction, vm.peek(argc), argc); err != nil {
+				return err
+			}
+
+			f = &vm.frames[len(vm.frames)-1]
+			code = f.closure.Function.Chunk.Code
+			constants = f.closure.Function.Chunk.Constants
+			globals = f.closure.globals
+		case bytecode.OpClosure:
+			closure := newClosure(constants[readShort()].(*bytecode.Function), globals, f.closure.file)
+
+			for n := range closure.upvalues {
+				isLocal, index := code[f.ip], int(code[f.ip+1])
+				f.ip += 2
+
+				if isLocal == 1 {
+					closure.upvalues[n] = vm.captureUpvalue(f.base + index)
+				} else {
+					closure.upvalues[n] = f.closure.upvalues[index]
+				}
+			}
+
+			vm.push(closure)
+		case bytecode.OpTry:
+			offset := readShort()
+			vm.handlers = append(vm.handlers, handler{len(vm.frames) - 1, f.ip + offset, len(vm.stack)})
+		case bytecode.OpEndTry:
+			vm.handlers = vm.handlers[:len(vm.handlers)-1]
+		case bytecode.OpThrow:
+			return vm.throw(instruction, vm.pop())
+		case bytecode.OpCloseUpvalue:
+			vm.closeUpvalues(len(vm.stack) - 1)
+			vm.pop()
+		case bytecode.OpReturn:
+			result := vm.pop()
+			vm.closeUpvalues(f.base)
+			vm.stack = vm.stack[:f.base]
+			vm.frames = vm.frames[:len(vm.frames)-1]
+
+			// Compiled code removes its tries before returning, this only guards the stack
+			for len(vm.handlers) > 0 && vm.handlers[len(vm.handlers)-1].frame >= len(vm.frames) {
+				vm.handlers = vm.handlers[:len(vm.handlers)-1]
+			}
+
+			if len(vm.frames) == 0 {
+				return nil
+			}
+
+			vm.push(result)
+
+			if len(vm.frames) == depth {
+				return nil
+			}
+
+			f = &vm.frames[len(vm.frames)-1]
+			code = f.closure.Function.Chunk.Code
+			constants = f.closure.Function.Chunk.Constants
+			globals = f.closure.globals
+		case bytecode.OpClass:
+			vm.push(newClass(constants[readShort()].(string)))
+		case bytecode.OpInherit:
+			superclass, ok := vm.peek(1).(*Class)
+
+			if !ok {
+				return vm.runtimeError(instruction, "Superclass must be a class.")
+			}
+
+			// Methods are fixed once a class is declared, so inheriting copies them down
+			subclass := vm.pop().(*Class)
+			for name, method := range superclass.methods {
+				subclass.methods[name] = method
+			}
+		case bytecode.OpMethod:
+			name := constants[readShort()].(string)
+			method := vm.pop().(*Closure)
+			vm.peek(0).(*Class).methods[name] = method
+		case bytecode.OpList:
+			count := readShort()
+			elements := make([]interface{}, count)
+			copy(elements, vm.stack[len(vm.stack)-count:])
+			vm.stack = vm.stack[:len(vm.stack)-count]
+			vm.push(interpreter.NewList(elements))
+		case bytecode.OpMap:
+			count := readShort()
+			entries := vm.stack[len(vm.stack)-2*count:]
+			m := interpreter.NewMap()
+
+			for n := 0; n < len(entries); n += 2 {
+				if err := m.SetIndex(entries[n], entries[n+1]); err != nil {
+					return vm.runtimeError(instruction, err.Error())
+				}
+			}
+
+			vm.stack = vm.stack[:len(vm.stack)-2*count]
+			vm.push(m)
+		case bytecode.OpImport:
+			path := constants[readShort()].(string)
+			imported, err := vm.loader.Import(vm.file, path, vm.runModule)
+
+			if err != nil {
+				// Errors pointing into the module are reported there rather than at the import
+				if module.Located(err) {
+					return err
+				}
+
+				return vm.runtimeError(instruction, err.Error())
+			}
+
+			// Running the module may have grown the frame stack
+			f = &vm.frames[len(vm.frames)-1]
+			vm.push(imported)
+		default:
+			return vm.runtimeError(instruction, fmt.Sprintf("Unknown instruction %d.", op))
+		}
+	}
+}
+
+// operators maps binary instructions to the operator the interpreter evaluates them with
+var operators = map[bytecode.OpCode]tokens.TokenType{
+	bytecode.OpGreater:      tokens.TokenGreater,
+	bytecode.OpGreaterEqual: tokens.TokenGreaterEqual,
+	bytecode.OpLess:         tokens.TokenLess,
+	bytecode.OpLessEqual:    tokens.TokenLessEqual,
+	bytecode.OpAdd:          tokens.TokenPlus,
+	bytecode.OpSubtract:     tokens.TokenMinus,
+	bytecode.OpMultiply:     tokens.TokenStar,
+	bytecode.OpDivide:       tokens.TokenSlash,
+	bytecode.OpModulo:       tokens.TokenModulo,
+	bytecode.OpBitAnd:       tokens.TokenAmpersand,
+	bytecode.OpBitOr:        tokens.TokenPipe,
+	bytecode.OpBitXor:       tokens.TokenCaret,
+	bytecode.OpShiftLeft:    tokens.TokenLessLess,
+	bytecode.OpShiftRight:   tokens.TokenGreaterGreater,
+}
+
+// callValue calls the callee sitting below argc arguments on the stack
+func (vm *VM) callValue(instruction int, callee interface{}, argc int) error {
+	switch c := callee.(type) {
+	case *Closure:
+		return vm.call(instruction, c, argc)
+	case *BoundMethod:
+		vm.stack[len(vm.stack)-argc-1] = c.receiver
+		return vm.call(instruction, c.method, argc)
+	case *Class:
+		vm.stack[len(vm.stack)-argc-1] = newInstance(c)
+
+		if initializer, ok := c.methods["init"]; ok {
+			return vm.call(instruction, initializer, argc)
+		}
+
+		if argc != 0 {
+			return vm.runtimeError(instruction, fmt.Sprintf("Expected 0 arguments, but got %d.", argc))
+		}
+
+		return nil
+	case interpreter.Callable:
+		if !interpreter.AcceptsArguments(c, argc) {
+			return vm.runtimeError(instruction, fmt.Sprintf("Expected %d arguments, but got %d.", c.Arity(), argc))
+		}
+
+		arguments := make([]interface{}, argc)
+		copy(arguments, vm.stack[len(vm.stack)-argc:])
+
+		f := vm.frames[len(vm.frames)-1]
+		value, err := vm.host.CallNative(c, arguments, vm.trace(), f.closure.token(instruction))
+
+		if err != nil {
+			return err
+		}
+
+		vm.stack = vm.stack[:len(vm.stack)-argc-1]
+		vm.push(value)
+		return nil
+	}
+
+	return vm.runtimeError(instruction, "Only function and class types are callable.")
+}
+
+// call pushes a frame for closure, its arguments are already on the stack. instruction is the
+// calling instruction of the current frame, errors are reported at its line.
+func (vm *VM) call(instruction int, closure *Closure, argc int) error {
+	if argc != closure.Function.Arity {
+		return vm.runtimeError(instruction, fmt.Sprintf("Expected %d arguments, but got %d.", closure.Function.Arity, argc))
+	}
+
+	if len(vm.frames) == maxFrames {
+		return vm.runtimeError(instruction, "Stack overflow.")
+	}
+
+	vm.frames = append(vm.frames, frame{closure, 0, len(vm.stack) - argc - 1})
+	return nil
+}
+
+func (vm *VM) getUpvalue(up *upvalue) interface{} {
+	if up.open {
+		return vm.stack[up.slot]
+	}
+
+	return up.closed
+}
+
+func (vm *VM) setUpvalue(up *upvalue, value interface{}) {
+	if up.open {
+		vm.stack[up.slot] = value
+		return
+	}
+
+	up.closed = value
+}
+
+// captureUpvalue reuses the open upvalue for slot so closures share the variable
+func (vm *VM) captureUpvalue(slot int) *upvalue {
+	var previous *upvalue
+	current := vm.openUpvalues
+
+	for current != nil && current.slot > slot {
+		previous, current = current, current.next
+	}
+
+	if current != nil && current.slot == slot {
+		return current
+	}
+
+	created := &upvalue{slot, true, nil, current}
+
+	if previous == nil {
+		vm.openUpvalues = created
+	} else {
+		previous.next = created
+	}
+
+	return created
+}
+
+// closeUpvalues moves every variable at or above last off of the stack into its upvalue
+func (vm *VM) closeUpvalues(last int) {
+	for vm.openUpvalues != nil && vm.openUpvalues.slot >= last {
+		up := vm.openUpvalues
+		up.closed = vm.stack[up.slot]
+		up.open = false
+		vm.openUpvalues = up.next
+	}
+}
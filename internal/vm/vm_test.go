@@ -0,0 +1,171 @@
+package vm
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jparr721/obsidian/internal/bytecode"
+	"github.com/jparr721/obsidian/internal/compiler"
+	"github.com/jparr721/obsidian/internal/interpreter"
+	"github.com/jparr721/obsidian/internal/parser"
+	"github.com/jparr721/obsidian/internal/resolver"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+type vmTest struct {
+	Name     string
+	Source   string
+	Expected string
+}
+
+// run compiles src and runs it on vm, returning everything it printed
+func run(t *testing.T, vm *VM, src string) (string, error) {
+	toks, tokErr := tokens.NewTokenizer(src).ScanTokens()
+	if tokErr != nil {
+		t.Fatalf("failed to tokenize test source: %v", tokErr)
+	}
+
+	statements, parseErrs := parser.NewParser(toks).Parse()
+	if len(parseErrs) > 0 {
+		t.Fatalf("failed to parse test source: %v", parseErrs)
+	}
+
+	if _, errs := resolver.NewResolver().Resolve(statements); len(errs) > 0 {
+		t.Fatalf("failed to resolve test source: %v", errs[0])
+	}
+
+	script, errs := compiler.NewCompiler().Compile(statements)
+	if len(errs) > 0 {
+		t.Fatalf("failed to compile test source: %v", errs[0])
+	}
+
+	out := &bytes.Buffer{}
+	vm.SetOutput(out)
+	err := vm.Run(script)
+
+	return out.String(), err
+}
+
+func TestRun(t *testing.T) {
+	tests := []vmTest{
+		{"upvalues close when their scope ends", "var f; { var x = 1; f = fun () { x = x + 1; return x; }; } print f(); print f();", "2\n3\n"},
+		{"closures share a captured variable", "fun make() { var n = 0; return [fun () { n = n + 1; }, fun () { return n; }]; } var p = make(); p[0](); p[0](); print p[1]();", "2\n"},
+		{"break pops the loop's locals", "var i = 0; while (true) { var a = 1; { var b = 2; if (i == 2) { break; } } i = i + 1; } print i;", "2\n"},
+		{"break closes captured locals", "var f; while (true) { var x = \"kept\"; f = fun () { return x; }; break; } print f();", "kept\n"},
+		{"initializers return the instance", "class A { init() { this.v = 1; } } var a = A(); print a.init().v;", "1\n"},
+		{"fields shadow methods", "class A { m() { return 1; } } var a = A(); a.m = fun () { return 2; }; print a.m();", "2\n"},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+
+		output, err := run(t, NewVM(), test.Source)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if output != test.Expected {
+			t.Errorf("expected %q but got %q", test.Expected, output)
+		}
+	}
+}
+
+func TestGlobalsPersistBetweenRuns(t *testing.T) {
+	vm := NewVM()
+
+	if _, err := run(t, vm, "var count = 1; fun bump() { count = count + 1; }"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed run must leave the machine usable
+	if _, err := run(t, vm, "bump(); missing();"); err == nil {
+		t.Fatal("expected an undefined variable error")
+	}
+
+	output, err := run(t, vm, "bump(); print count;")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if output != "3\n" {
+		t.Errorf("expected 3 but got %q", output)
+	}
+}
+
+func TestStackOverflow(t *testing.T) {
+	_, err := run(t, NewVM(), "fun recurse() { recurse(); }\nrecurse();")
+
+	if err == nil || !strings.Contains(err.Error(), "[line 1] Stack overflow.") {
+		t.Errorf("expected a stack overflow error but got %v", err)
+	}
+}
+
+func TestMalformedCodeFailsTheRun(t *testing.T) {
+	// Code that never went through the verifier reads a local far above the stack
+	script := bytecode.NewFunction("")
+	script.Chunk.Write(byte(bytecode.OpGetLocal), 1)
+	script.Chunk.Write(0xff, 1)
+	script.Chunk.Write(byte(bytecode.OpReturn), 1)
+
+	vm := NewVM()
+	err := vm.Run(script)
+
+	if err == nil || !strings.Contains(err.Error(), "[line 1] Invalid bytecode") {
+		t.Fatalf("expected an invalid bytecode error but got %v", err)
+	}
+
+	output, err := run(t, vm, "print 1;")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if output != "1\n" {
+		t.Errorf("expected 1 but got %q", output)
+	}
+}
+
+func TestNativesSeeFrames(t *testing.T) {
+	vm := NewVM()
+
+	var frames []interpreter.Frame
+	vm.globals.values["where"] = interpreter.NewNativeFunction("where", 0, func(i *interpreter.Interpreter, arguments []interface{}) (interface{}, error) {
+		frames = i.Frames()
+		return nil, nil
+	})
+
+	if _, err := run(t, vm, "fun outer() {\n  where();\n}\nouter();"); err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0)
+	for _, frame := range frames {
+		names = append(names, fmt.Sprintf("%s %d", frame.Name, frame.Call.Line))
+	}
+
+	if expected := "outer 4, where 2"; strings.Join(names, ", ") != expected {
+		t.Errorf("expected frames %s but got %s", expected, strings.Join(names, ", "))
+	}
+}
+
+func TestSeedIsPerVM(t *testing.T) {
+	seeded, other := NewVM(), NewVM()
+
+	expected, err := run(t, seeded, "seed(7); print random();")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := run(t, seeded, "seed(7);"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := run(t, other, "random();"); err != nil {
+		t.Fatal(err)
+	}
+
+	if output, err := run(t, seeded, "print random();"); err != nil || output != expected {
+		t.Errorf("expected the seeded value %q but got %q, %v", expected, output, err)
+	}
+}
@@ -0,0 +1,263 @@
+package obsidian
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/jparr721/obsidian/internal/interpreter"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// toValue converts a Go value into the obsidian runtime's representation
+func toValue(value reflect.Value) (interface{}, error) {
+	if !value.IsValid() {
+		return nil, nil
+	}
+
+	switch value.Kind() {
+	case reflect.Bool:
+		return value.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Uint() > math.MaxInt64 {
+			return nil, fmt.Errorf("%d does not fit in an integer", value.Uint())
+		}
+
+		return int64(value.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), nil
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Slice, reflect.Array:
+		elements := make([]interface{}, 0, value.Len())
+		for n := 0; n < value.Len(); n++ {
+			element, err := toValue(value.Index(n))
+
+			if err != nil {
+				return nil, err
+			}
+
+			elements = append(elements, element)
+		}
+
+		return interpreter.NewList(elements), nil
+	case reflect.Map:
+		m := interpreter.NewMap()
+		iter := value.MapRange()
+		for iter.Next() {
+			key, err := toValue(iter.Key())
+
+			if err != nil {
+				return nil, err
+			}
+
+			if !interpreter.Hashable(key) {
+				return nil, fmt.Errorf("unsupported map key type %s", iter.Key().Type())
+			}
+
+			element, err := toValue(iter.Value())
+
+			if err != nil {
+				return nil, err
+			}
+
+			m.Set(key, element)
+		}
+
+		return m, nil
+	case reflect.Interface, reflect.Ptr:
+		if value.IsNil() {
+			return nil, nil
+		}
+
+		// Runtime values such as lists, instances and functions pass through untouched
+		if value.Kind() == reflect.Ptr {
+			return value.Interface(), nil
+		}
+
+		return toValue(value.Elem())
+	}
+
+	return nil, fmt.Errorf("unsupported type %s", value.Type())
+}
+
+// fromValue converts a runtime value into plain Go values for the host
+func fromValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *interpreter.List:
+		elements := make([]interface{}, 0, len(v.Elements))
+		for _, element := range v.Elements {
+			elements = append(elements, fromValue(element))
+		}
+
+		return elements
+	case *interpreter.Map:
+		m := make(map[interface{}]interface{}, len(v.Keys()))
+		for _, key := range v.Keys() {
+			element, _ := v.Get(key)
+			m[key] = fromValue(element)
+		}
+
+		return m
+	}
+
+	return value
+}
+
+// convertArgument converts a runtime value into a Go value of type target
+func convertArgument(value interface{}, target reflect.Type) (reflect.Value, error) {
+	if target.Kind() == reflect.Interface {
+		if value == nil {
+			return reflect.Zero(target), nil
+		}
+
+		converted := reflect.ValueOf(fromValue(value))
+		if !converted.Type().Implements(target) {
+			return reflect.Value{}, fmt.Errorf("expected %s but got %s", target, interpreter.Stringify(value))
+		}
+
+		return converted, nil
+	}
+
+	switch target.Kind() {
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			return reflect.ValueOf(b).Convert(target), nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !interpreter.IsNumber(value) {
+			break
+		}
+
+		number, ok := value.(int64)
+		if float, isFloat := value.(float64); isFloat {
+			number, ok = int64(float), float == math.Trunc(float) && float >= math.MinInt64 && float < math.MaxInt64
+		}
+
+		converted := reflect.New(target).Elem()
+		switch {
+		case !ok:
+			return reflect.Value{}, fmt.Errorf("expected a whole number but got %s", interpreter.Stringify(value))
+		case target.Kind() >= reflect.Uint && (number < 0 || converted.OverflowUint(uint64(number))):
+			return reflect.Value{}, fmt.Errorf("%s does not fit in %s", interpreter.Stringify(value), target)
+		case target.Kind() < reflect.Uint && converted.OverflowInt(number):
+			return reflect.Value{}, fmt.Errorf("%s does not fit in %s", interpreter.Stringify(value), target)
+		}
+
+		return reflect.ValueOf(number).Convert(target), nil
+	case reflect.Float32, reflect.Float64:
+		switch number := value.(type) {
+		case int64:
+			return reflect.ValueOf(float64(number)).Convert(target), nil
+		case float64:
+			return reflect.ValueOf(number).Convert(target), nil
+		}
+	case reflect.String:
+		if s, ok := value.(string); ok {
+			return reflect.ValueOf(s).Convert(target), nil
+		}
+	case reflect.Slice:
+		if list, ok := value.(*interpreter.List); ok {
+			slice := reflect.MakeSlice(target, 0, len(list.Elements))
+			for _, element := range list.Elements {
+				converted, err := convertArgument(element, target.Elem())
+
+				if err != nil {
+					return reflect.Value{}, err
+				}
+
+				slice = reflect.Append(slice, converted)
+			}
+
+			return slice, nil
+		}
+	case reflect.Map:
+		if m, ok := value.(*interpreter.Map); ok {
+			converted := reflect.MakeMapWithSize(target, len(m.Keys()))
+			for _, key := range m.Keys() {
+				k, err := convertArgument(key, target.Key())
+
+				if err != nil {
+					return reflect.Value{}, err
+				}
+
+				element, _ := m.Get(key)
+				v, err := convertArgument(element, target.Elem())
+
+				if err != nil {
+					return reflect.Value{}, err
+				}
+
+				converted.SetMapIndex(k, v)
+			}
+
+			return converted, nil
+		}
+	case reflect.Ptr:
+		if reflect.TypeOf(value) == target {
+			return reflect.ValueOf(value), nil
+		}
+	}
+
+	return reflect.Value{}, fmt.Errorf("expected %s but got %s", target, interpreter.Stringify(value))
+}
+
+// wrap turns a Go function into a native the interpreter can call
+func wrap(name string, fn reflect.Value) (*interpreter.NativeFunction, error) {
+	if fn.Kind() != reflect.Func || fn.IsNil() {
+		return nil, errors.New("expected a function")
+	}
+
+	fnType := fn.Type()
+
+	if fnType.IsVariadic() {
+		return nil, errors.New("variadic functions are not supported")
+	}
+
+	switch {
+	case fnType.NumOut() > 2:
+		return nil, errors.New("functions may return at most a value and an error")
+	case fnType.NumOut() == 2 && fnType.Out(1) != errorType:
+		return nil, errors.New("the second return value must be an error")
+	}
+
+	call := func(i *interpreter.Interpreter, arguments []interface{}) (interface{}, error) {
+		in := make([]reflect.Value, 0, len(arguments))
+		for n, argument := range arguments {
+			converted, err := convertArgument(argument, fnType.In(n))
+
+			if err != nil {
+				return nil, fmt.Errorf("%s() argument %d: %v.", name, n+1, err)
+			}
+
+			in = append(in, converted)
+		}
+
+		return results(fn.Call(in))
+	}
+
+	return interpreter.NewNativeFunction(name, fnType.NumIn(), call), nil
+}
+
+// results splits a Go function's return values into a runtime value and an error
+func results(out []reflect.Value) (interface{}, error) {
+	var value reflect.Value
+
+	for _, result := range out {
+		if result.Type() == errorType {
+			if !result.IsNil() {
+				return nil, result.Interface().(error)
+			}
+			continue
+		}
+
+		value = result
+	}
+
+	return toValue(value)
+}
@@ -0,0 +1,196 @@
+// Package obsidian embeds the obsidian scripting language in Go programs.
+//
+// A VM keeps its globals between calls to Eval, so a host can register Go functions and
+// values once and then run many scripts against them:
+//
+//	vm := obsidian.New()
+//	vm.Register("greet", func(name string) string { return "hello " + name })
+//	value, err := vm.Eval(`greet("world");`)
+package obsidian
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/jparr721/obsidian/internal/interpreter"
+	"github.com/jparr721/obsidian/internal/parser"
+	"github.com/jparr721/obsidian/internal/resolver"
+	"github.com/jparr721/obsidian/internal/statement"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+// VM is an embedded obsidian interpreter whose globals persist between evaluations
+type VM struct {
+	interpreter *interpreter.Interpreter
+}
+
+// New creates a VM with the builtin natives defined
+func New() *VM {
+	return &VM{interpreter.NewInterpreter()}
+}
+
+// Error collects every problem reported while compiling a script
+type Error struct {
+	Errors []error
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// SetOutput redirects print statements to w, they go to stdout by default
+func (vm *VM) SetOutput(w io.Writer) {
+	vm.interpreter.SetOutput(w)
+}
+
+// SetGlobal converts a Go value and binds it to name in the script's global scope
+func (vm *VM) SetGlobal(name string, value interface{}) error {
+	converted, err := toValue(reflect.ValueOf(value))
+
+	if err != nil {
+		return fmt.Errorf("obsidian: global '%s': %v", name, err)
+	}
+
+	vm.interpreter.Define(name, converted)
+	return nil
+}
+
+// Global reads a global and converts it back to a Go value. Integers come back as int64,
+// floats as float64, lists as []interface{} and maps as map[interface{}]interface{}.
+func (vm *VM) Global(name string) (interface{}, bool) {
+	value, ok := vm.interpreter.Global(name)
+
+	if !ok {
+		return nil, false
+	}
+
+	return fromValue(value), true
+}
+
+// Register exposes a Go function to scripts under name. Arguments are converted to the
+// function's parameter types, a mismatch is reported as a runtime error at the call site.
+// The function may return nothing, a value, an error, or a value and an error.
+func (vm *VM) Register(name string, fn interface{}) error {
+	native, err := wrap(name, reflect.ValueOf(fn))
+
+	if err != nil {
+		return fmt.Errorf("obsidian: register '%s': %v", name, err)
+	}
+
+	vm.interpreter.Define(name, native)
+	return nil
+}
+
+// Eval runs src against the VM's globals. When the last statement is a bare expression its
+// value is converted and returned.
+func (vm *VM) Eval(src string) (interface{}, error) {
+	statements, err := vm.compile(src)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(statements) == 0 {
+		return nil, nil
+	}
+
+	last, isExpression := statements[len(statements)-1].(*statement.ExpressionStatement)
+
+	if isExpression {
+		statements = statements[:len(statements)-1]
+	}
+
+	if err := vm.interpreter.Interpret(statements); err != nil {
+		return nil, err
+	}
+
+	if !isExpression {
+		return nil, nil
+	}
+
+	value, err := vm.interpreter.Evaluate(last.Expression)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return fromValue(value), nil
+}
+
+// Call invokes a global function or class with Go arguments
+func (vm *VM) Call(name string, arguments ...interface{}) (interface{}, error) {
+	value, ok := vm.interpreter.Global(name)
+
+	if !ok {
+		return nil, fmt.Errorf("obsidian: undefined global '%s'", name)
+	}
+
+	callable, ok := value.(interpreter.Callable)
+
+	if !ok {
+		return nil, fmt.Errorf("obsidian: global '%s' is not callable", name)
+	}
+
+	if !interpreter.AcceptsArguments(callable, len(arguments)) {
+		return nil, fmt.Errorf("obsidian: '%s' expects %d arguments but got %d", name, callable.Arity(), len(arguments))
+	}
+
+	converted := make([]interface{}, 0, len(arguments))
+	for n, argument := range arguments {
+		value, err := toValue(reflect.ValueOf(argument))
+
+		if err != nil {
+			return nil, fmt.Errorf("obsidian: argument %d to '%s': %v", n+1, name, err)
+		}
+
+		converted = append(converted, value)
+	}
+
+	result, err := vm.interpreter.Call(callable, converted)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return fromValue(result), nil
+}
+
+func (vm *VM) compile(src string) ([]statement.Statement, error) {
+	toks, tokErr := tokens.NewTokenizer(src).ScanTokens()
+
+	if tokErr != nil {
+		return nil, &Error{[]error{tokErr}}
+	}
+
+	statements, parseErrs := parser.NewParser(toks).Parse()
+
+	if len(parseErrs) > 0 {
+		errs := make([]error, 0, len(parseErrs))
+		for _, err := range parseErrs {
+			errs = append(errs, err)
+		}
+
+		return nil, &Error{errs}
+	}
+
+	locals, resolveErrs := resolver.NewResolver().Resolve(statements)
+
+	if len(resolveErrs) > 0 {
+		errs := make([]error, 0, len(resolveErrs))
+		for _, err := range resolveErrs {
+			errs = append(errs, err)
+		}
+
+		return nil, &Error{errs}
+	}
+
+	vm.interpreter.Resolve(locals)
+	return statements, nil
+}
@@ -0,0 +1,217 @@
+package obsidian
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type evalTest struct {
+	Name     string
+	Source   string
+	Expected interface{}
+}
+
+func TestEval(t *testing.T) {
+	vm := New()
+
+	tests := []evalTest{
+		{"expression value", "1 + 2;", int64(3)},
+		{"globals persist", "var x = 10;", nil},
+		{"reads persisted global", "x * 2;", int64(20)},
+		{"floats", "x / 4.0;", 2.5},
+		{"strings", `"a" + "b";`, "ab"},
+		{"lists convert", "[1, true, nil];", []interface{}{int64(1), true, nil}},
+		{"maps convert", `({"a": 1});`, map[interface{}]interface{}{"a": int64(1)}},
+		{"trailing statement", "print 1;", nil},
+	}
+
+	vm.SetOutput(&bytes.Buffer{})
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+		value, err := vm.Eval(test.Source)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(value, test.Expected) {
+			t.Errorf("expected %#v but got %#v", test.Expected, value)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	vm := New()
+
+	tests := []evalTest{
+		{"parse error", "var = 1;", "Expected variable name."},
+		{"resolve error", "return 1;", "Can't return from top-level code."},
+		{"runtime error", "-\"a\";", "Operans must be numbers."},
+	}
+
+	for _, test := range tests {
+		t.Logf("Running: %s\n", test.Name)
+		_, err := vm.Eval(test.Source)
+
+		if err == nil || !strings.Contains(err.Error(), test.Expected.(string)) {
+			t.Errorf("expected error containing %q but got %v", test.Expected, err)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	vm := New()
+	out := &bytes.Buffer{}
+	vm.SetOutput(out)
+
+	if err := vm.Register("greet", func(name string) string { return "hello " + name }); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := vm.Register("sum", func(values []int) int {
+		total := 0
+		for _, v := range values {
+			total += v
+		}
+		return total
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := vm.Register("fail", func() error { return errors.New("host failure") }); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := vm.Eval(`print greet("world"); print sum([1, 2, 3]);`); err != nil {
+		t.Fatal(err)
+	}
+
+	if out.String() != "hello world\n6\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	if _, err := vm.Eval(`greet(1);`); err == nil || !strings.Contains(err.Error(), "greet() argument 1") {
+		t.Errorf("expected an argument type error but got %v", err)
+	}
+
+	if _, err := vm.Eval(`fail();`); err == nil || !strings.Contains(err.Error(), "host failure") {
+		t.Errorf("expected the host error but got %v", err)
+	}
+
+	if err := vm.Register("bad", 42); err == nil {
+		t.Error("expected registering a non function to fail")
+	}
+
+	if err := vm.Register("bad", func() (int, int) { return 0, 0 }); err == nil {
+		t.Error("expected a non error second return value to fail")
+	}
+}
+
+func TestGlobalsAndCall(t *testing.T) {
+	vm := New()
+
+	if err := vm.SetGlobal("limit", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := vm.Eval(`fun scale(n) { return n * limit; }`); err != nil {
+		t.Fatal(err)
+	}
+
+	value, err := vm.Call("scale", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value != int64(15) {
+		t.Errorf("expected 15 but got %v", value)
+	}
+
+	if _, err := vm.Call("scale"); err == nil {
+		t.Error("expected an arity error")
+	}
+
+	if _, err := vm.Call("missing"); err == nil {
+		t.Error("expected an undefined global error")
+	}
+
+	if limit, ok := vm.Global("limit"); !ok || limit != int64(3) {
+		t.Errorf("expected limit to be 3 but got %v", limit)
+	}
+}
+
+func TestNumberTypes(t *testing.T) {
+	vm := New()
+
+	if _, err := vm.Eval("var count = 2 + 3; var ratio = count / 2.0;"); err != nil {
+		t.Fatal(err)
+	}
+
+	count, _ := vm.Global("count")
+	if _, ok := count.(int64); !ok {
+		t.Errorf("expected an int64 but got %T", count)
+	}
+
+	ratio, _ := vm.Global("ratio")
+	if _, ok := ratio.(float64); !ok {
+		t.Errorf("expected a float64 but got %T", ratio)
+	}
+
+	value, err := vm.Eval("count * 2;")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := value.(int64); !ok {
+		t.Errorf("expected Eval to return an int64 but got %T", value)
+	}
+}
+
+func TestFrames(t *testing.T) {
+	vm := New()
+
+	var seen []Frame
+	if err := vm.Register("where", func() { seen = vm.Frames() }); err != nil {
+		t.Fatal(err)
+	}
+
+	src := "fun a() {\n  where();\n}\nfun b() {\n  a();\n}\nb();"
+	if _, err := vm.Eval(src); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Frame{{"<script>", 7, 3}, {"b", 5, 5}, {"a", 2, 9}}
+	if !reflect.DeepEqual(seen, expected) {
+		t.Errorf("expected frames %v but got %v", expected, seen)
+	}
+
+	if frames := vm.Frames(); len(frames) != 0 {
+		t.Errorf("expected no frames outside a call but got %v", frames)
+	}
+}
+
+func TestTraceback(t *testing.T) {
+	vm := New()
+
+	_, err := vm.Eval("fun inner() {\n  return 1 + nil;\n}\nfun outer() {\n  return inner();\n}\nouter();")
+	if err == nil {
+		t.Fatal("expected a runtime error")
+	}
+
+	expected := []Frame{{"<script>", 7, 7}, {"outer", 5, 16}, {"inner", 2, 12}}
+	if trace := Traceback(err); !reflect.DeepEqual(trace, expected) {
+		t.Errorf("expected traceback %v but got %v", expected, trace)
+	}
+
+	if _, err := vm.Call("inner"); !reflect.DeepEqual(Traceback(err), []Frame{{"inner", 2, 12}}) {
+		t.Errorf("expected a call from Go to start at the function but got %v", Traceback(err))
+	}
+
+	if trace := Traceback(errors.New("plain")); trace != nil {
+		t.Errorf("expected no traceback for a plain error but got %v", trace)
+	}
+}
@@ -0,0 +1,42 @@
+package obsidian
+
+import (
+	"github.com/jparr721/obsidian/internal/diagnostics"
+	"github.com/jparr721/obsidian/internal/interpreter"
+	"github.com/jparr721/obsidian/internal/tokens"
+)
+
+// Frame is a function on a script's call stack and where in it execution was. The top level of
+// a script is named <script>.
+type Frame struct {
+	Function string
+	Line     int
+	Column   int
+}
+
+// Frames returns the script's call stack, innermost last. Inside a registered Go function it
+// shows how the script got there, the last frame is at the call to the Go function.
+func (vm *VM) Frames() []Frame {
+	return frames(interpreter.Traceback(vm.interpreter.Frames(), tokens.Token{}))
+}
+
+// Traceback returns the call stack a runtime error from Eval or Call was raised in, innermost
+// last and ending where the error happened. Other errors have no traceback.
+func Traceback(err error) []Frame {
+	runtimeErr, ok := err.(*interpreter.RuntimeError)
+
+	if !ok {
+		return nil
+	}
+
+	return frames(runtimeErr.Traceback())
+}
+
+func frames(trace []diagnostics.Frame) []Frame {
+	converted := make([]Frame, 0, len(trace))
+	for _, frame := range trace {
+		converted = append(converted, Frame{frame.Name, frame.Span.Line, frame.Span.Column})
+	}
+
+	return converted
+}
@@ -0,0 +1,195 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jparr721/obsidian/internal/diagnostics"
+	"github.com/jparr721/obsidian/internal/runtime"
+)
+
+var (
+	expectOutput = regexp.MustCompile(`// expect: (.*)$`)
+	expectError  = regexp.MustCompile(`// expect runtime error: (.*)$`)
+
+	// expectSpan is a comment of carets under the line with the runtime error, marking where
+	// the error points
+	expectSpan = regexp.MustCompile(`^//\s*(\^+)\s*$`)
+)
+
+type conformanceTest struct {
+	Name     string
+	Expected []string
+	Error    string
+
+	// Span is where the runtime error points, a zero Line means it is not checked
+	Span diagnostics.Span
+}
+
+// loadExpectations reads the `// expect:` comments out of a conformance program
+func loadExpectations(t *testing.T, path string) conformanceTest {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	test := conformanceTest{Name: filepath.Base(path), Expected: make([]string, 0)}
+	scanner := bufio.NewScanner(file)
+	errorLine := 0
+
+	for line := 1; scanner.Scan(); line++ {
+		if match := expectOutput.FindStringSubmatch(scanner.Text()); match != nil {
+			test.Expected = append(test.Expected, match[1])
+		}
+
+		if match := expectError.FindStringSubmatch(scanner.Text()); match != nil {
+			test.Error = fmt.Sprintf("RuntimeError: [line %d] %s", line, match[1])
+			errorLine = line
+		}
+
+		if match := expectSpan.FindStringSubmatchIndex(scanner.Text()); match != nil && errorLine == line-1 {
+			test.Span = diagnostics.Span{Line: errorLine, Column: match[2] + 1, Length: match[3] - match[2]}
+		}
+	}
+
+	return test
+}
+
+// runner executes a conformance program, collecting its output and errors on rt
+type runner struct {
+	Name string
+	Run  func(t *testing.T, rt *runtime.ObcRT, path string)
+}
+
+var runners = []runner{
+	{"tree", func(t *testing.T, rt *runtime.ObcRT, path string) {
+		rt.SetBackend(runtime.BackendTree)
+		rt.Run(path)
+	}},
+	{"vm", func(t *testing.T, rt *runtime.ObcRT, path string) {
+		rt.SetBackend(runtime.BackendVM)
+		rt.Run(path)
+	}},
+	{"object file", func(t *testing.T, rt *runtime.ObcRT, path string) {
+		object := filepath.Join(t.TempDir(), runtime.ObjectPath(filepath.Base(path)))
+
+		// Build in a separate runtime so only errors from running the object file are seen
+		build := runtime.NewObcRT()
+		build.Build(path, object)
+		if build.DidError() {
+			t.Fatalf("failed to build %s: %v", path, build.Errors())
+		}
+
+		rt.Run(object)
+	}},
+}
+
+// TestConformance runs every program under conformance/ on each backend
+func TestConformance(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("conformance", "*.ob"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paths) == 0 {
+		t.Fatal("no conformance programs found")
+	}
+
+	for _, r := range runners {
+		for _, path := range paths {
+			test := loadExpectations(t, path)
+			t.Logf("Running: %s on %s\n", test.Name, r.Name)
+
+			out := &bytes.Buffer{}
+			rt := runtime.NewObcRT()
+			rt.SetOutput(out)
+
+			// Object files are built elsewhere, so imports fall back to the search path
+			rt.AddSearchPath("conformance")
+			r.Run(t, rt, path)
+
+			output := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+			if out.Len() == 0 {
+				output = []string{}
+			}
+
+			if strings.Join(output, "\n") != strings.Join(test.Expected, "\n") {
+				t.Errorf("%s on %s: expected output\n%s\nbut got\n%s", test.Name, r.Name, strings.Join(test.Expected, "\n"), out.String())
+			}
+
+			errs := make([]string, 0)
+			for _, err := range rt.Errors() {
+				errs = append(errs, err.Error())
+			}
+
+			if strings.Join(errs, "\n") != test.Error {
+				t.Errorf("%s on %s: expected error %q but got %q", test.Name, r.Name, test.Error, strings.Join(errs, "\n"))
+			}
+
+			if test.Span.Line > 0 && len(rt.Errors()) == 1 {
+				checkSpan(t, test, r.Name, rt.Errors()[0])
+			}
+		}
+	}
+}
+
+// checkSpan compares where err points with the carets under its expectation, files differ
+// between runners so only the position is compared
+func checkSpan(t *testing.T, test conformanceTest, runner string, err error) {
+	d, ok := err.(diagnostics.Diagnosable)
+	if !ok {
+		t.Errorf("%s on %s: expected a diagnostic but got %v", test.Name, runner, err)
+		return
+	}
+
+	span := d.Diagnostic().Span
+	span.File = ""
+
+	if span != test.Span {
+		t.Errorf("%s on %s: expected the error at %+v but got %+v", test.Name, runner, test.Span, span)
+	}
+}
+
+// TestTraceback checks every backend records the same call stack for an uncaught error
+func TestTraceback(t *testing.T) {
+	tests := []conformanceTest{
+		{Name: "error_traceback.ob", Expected: []string{"<script> 15:13", "anonymous 12:19", "init 7:29", "inner 2:12"}},
+		{Name: "error_import_traceback.ob", Expected: []string{"<script> 4:1", "<script> 6:6", "fail 3:12"}},
+	}
+
+	for _, test := range tests {
+		for _, r := range runners {
+			t.Logf("Running: %s traceback on %s\n", test.Name, r.Name)
+
+			rt := runtime.NewObcRT()
+			rt.SetOutput(&bytes.Buffer{})
+			rt.AddSearchPath("conformance")
+			r.Run(t, rt, filepath.Join("conformance", test.Name))
+
+			if len(rt.Errors()) != 1 {
+				t.Fatalf("%s on %s: expected one error but got %v", test.Name, r.Name, rt.Errors())
+			}
+
+			d, ok := rt.Errors()[0].(diagnostics.Diagnosable)
+			if !ok {
+				t.Fatalf("%s on %s: expected a diagnostic but got %v", test.Name, r.Name, rt.Errors()[0])
+			}
+
+			trace := make([]string, 0)
+			for _, frame := range d.Diagnostic().Trace {
+				trace = append(trace, fmt.Sprintf("%s %d:%d", frame.Name, frame.Span.Line, frame.Span.Column))
+			}
+
+			if strings.Join(trace, ", ") != strings.Join(test.Expected, ", ") {
+				t.Errorf("%s on %s: expected %v but got %v", test.Name, r.Name, test.Expected, trace)
+			}
+		}
+	}
+}